@@ -0,0 +1,98 @@
+package lru
+
+import "time"
+
+// pendingAdd is an Add waiting in a SafeCache's admission queue. A pendingAdd
+// with done set is a marker queued by Wait instead.
+type pendingAdd struct {
+	key    string
+	value  Value
+	expire time.Time
+	done   chan struct{}
+}
+
+// enqueue hands an add to the background goroutine, blocking while the
+// queue is full. It reports false, queuing nothing, once Close has been
+// called, and the caller applies the add itself.
+func (s *SafeCache) enqueue(key string, value Value, expire time.Time) bool {
+	return s.send(pendingAdd{key: key, value: value, expire: expire})
+}
+
+// send puts p on the queue unless it has been closed. The read lock keeps
+// Close from closing the queue while a send is blocked on it.
+func (s *SafeCache) send(p pendingAdd) bool {
+	s.qmu.RLock()
+	defer s.qmu.RUnlock()
+	if s.closed {
+		return false
+	}
+	s.queue <- p
+	return true
+}
+
+// drain applies queued adds until the queue is closed. Whatever is already
+// queued when it wakes up is applied under a single lock acquisition.
+func (s *SafeCache) drain() {
+	var done []chan struct{}
+	for p := range s.queue {
+		s.lock()
+	batch:
+		for {
+			if p.done != nil {
+				done = append(done, p.done)
+			} else {
+				s.lru.addChecked(p.key, p.value, p.expire)
+			}
+			select {
+			case next, ok := <-s.queue:
+				if !ok {
+					break batch
+				}
+				p = next
+			default:
+				break batch
+			}
+		}
+		s.sync()
+		s.mu.Unlock()
+		// 解锁后再通知 Wait，它返回时这批写入已经可见
+		for _, ch := range done {
+			close(ch)
+		}
+		done = done[:0]
+	}
+	close(s.drained)
+}
+
+// Wait blocks until every Add queued before it has been applied. It returns
+// at once if the cache was not created WithAsyncAdd, and after Close, which
+// applies the queue itself.
+func (s *SafeCache) Wait() {
+	if s.queue == nil {
+		return
+	}
+	done := make(chan struct{})
+	if !s.send(pendingAdd{done: done}) {
+		// 队列已关闭，Close 会等剩下的写入完成
+		<-s.drained
+		return
+	}
+	<-done
+}
+
+// Close applies the adds still queued and stops the background goroutine
+// started by WithAsyncAdd. Add and AddWithTTL called after Close, or while
+// it runs, are applied synchronously. It does nothing for a synchronous
+// cache.
+func (s *SafeCache) Close() {
+	if s.queue == nil {
+		return
+	}
+	s.closeOnce.Do(func() {
+		s.qmu.Lock()
+		s.closed = true
+		close(s.queue)
+		s.qmu.Unlock()
+		<-s.drained
+	})
+}
@@ -0,0 +1,94 @@
+package lru
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestAsyncAdd(t *testing.T) {
+	s := NewSafe(WithAsyncAdd(4), WithMaxEntries(100))
+	defer s.Close()
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				s.Add(strconv.Itoa(g*50+i), String("v"))
+			}
+		}(g)
+	}
+	wg.Wait()
+	s.Add("last", String("v"))
+	s.Wait()
+	// 写入和淘汰都在后台完成，Wait 之后应当可见
+	if s.Len() != 100 {
+		t.Fatalf("expected 100 entries after Wait, got %d", s.Len())
+	}
+	if _, ok := s.Get("last"); !ok {
+		t.Fatalf("the last add should be applied after Wait")
+	}
+}
+
+func TestAsyncAddClose(t *testing.T) {
+	s := NewSafe(WithAsyncAdd(16))
+	s.Add("key1", String("1234"))
+	s.AddWithTTL("key2", String("5678"), NoExpiration)
+	s.Close()
+	s.Close()
+	if s.Len() != 2 {
+		t.Fatalf("Close should apply queued adds, got %d entries", s.Len())
+	}
+}
+
+func TestAsyncAddChecked(t *testing.T) {
+	s := NewSafe(WithAsyncAdd(16))
+	defer s.Close()
+	if err := s.AddChecked("key1", nil); err != ErrNilValue {
+		t.Fatalf("AddChecked should run synchronously, got %v", err)
+	}
+	if err := s.AddChecked("key1", String("1")); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.Get("key1"); !ok {
+		t.Fatalf("AddChecked should be visible at once")
+	}
+	// 之前排队的写入不能覆盖同步写入
+	for i := 0; i < 16; i++ {
+		s.Add("key2", String("queued"))
+	}
+	if err := s.AddChecked("key2", String("checked")); err != nil {
+		t.Fatal(err)
+	}
+	s.Wait()
+	if v, ok := s.Get("key2"); !ok || v.(String) != "checked" {
+		t.Fatalf("a queued Add overwrote AddChecked, got %v", v)
+	}
+	s.Close()
+	if err := s.AddChecked("key3", String("3")); err != nil {
+		t.Fatalf("AddChecked after Close failed: %v", err)
+	}
+}
+
+func TestAsyncAddAfterClose(t *testing.T) {
+	s := NewSafe(WithAsyncAdd(1))
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			// 与 Close 并发的写入不能向已关闭的队列发送
+			for i := 0; i < 50; i++ {
+				s.Add(strconv.Itoa(g*50+i), String("v"))
+				s.AddChecked("checked"+strconv.Itoa(g), String("v"))
+			}
+		}(g)
+	}
+	s.Close()
+	wg.Wait()
+	s.AddWithTTL("late", String("v"), NoExpiration)
+	if _, ok := s.Get("late"); !ok || s.Len() != 205 {
+		t.Fatalf("adds after Close should be applied at once, got %d entries", s.Len())
+	}
+}
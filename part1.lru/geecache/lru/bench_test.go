@@ -0,0 +1,71 @@
+package lru
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func benchKeys(n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = "key:" + strconv.Itoa(i)
+	}
+	return keys
+}
+
+func BenchmarkAdd(b *testing.B) {
+	keys := benchKeys(1 << 12)
+	lru := NewWithOptions(WithMaxEntries(1 << 11))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lru.Add(keys[i&(len(keys)-1)], String("v"))
+	}
+}
+
+func BenchmarkAddUpdate(b *testing.B) {
+	keys := benchKeys(1 << 10)
+	lru := New(int64(0), nil)
+	for _, k := range keys {
+		lru.Add(k, String("v"))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lru.Add(keys[i&(len(keys)-1)], String("v"))
+	}
+}
+
+func BenchmarkAddWithMeta(b *testing.B) {
+	keys := benchKeys(1 << 10)
+	lru := New(int64(0), nil)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lru.AddWithMeta(keys[i&(len(keys)-1)], String("v"), i)
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	keys := benchKeys(1 << 10)
+	lru := New(int64(0), nil)
+	for _, k := range keys {
+		lru.Add(k, String("v"))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lru.Get(keys[i&(len(keys)-1)])
+	}
+}
+
+// BenchmarkAddMinRetention adds to a full cache whose entries are all still
+// within the retention window, the worst case for victim selection.
+func BenchmarkAddMinRetention(b *testing.B) {
+	keys := benchKeys(1 << 16)
+	lru := NewWithOptions(WithMaxEntries(50000), WithMinRetention(time.Hour))
+	for _, k := range keys[:50000] {
+		lru.Add(k, String("v"))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lru.Add(keys[i&(len(keys)-1)], String("v"))
+	}
+}
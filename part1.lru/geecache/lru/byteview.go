@@ -0,0 +1,69 @@
+package lru
+
+import "encoding/json"
+
+// ByteView holds an immutable view of bytes.
+type ByteView struct {
+	b []byte
+}
+
+// NewByteView returns a ByteView holding a copy of b.
+func NewByteView(b []byte) ByteView {
+	return ByteView{b: cloneBytes(b)}
+}
+
+// Len returns the view's length
+func (v ByteView) Len() int {
+	return len(v.b)
+}
+
+// ByteSlice returns a copy of the data as a byte slice.
+func (v ByteView) ByteSlice() []byte {
+	return cloneBytes(v.b)
+}
+
+// String returns the data as a string, making a copy if necessary.
+func (v ByteView) String() string {
+	return string(v.b)
+}
+
+// MarshalJSON encodes the bytes like a []byte, as a base64 string, so
+// ByteView values survive Dump.
+func (v ByteView) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.b)
+}
+
+// UnmarshalJSON decodes the form written by MarshalJSON.
+func (v *ByteView) UnmarshalJSON(data []byte) error {
+	var b []byte
+	if err := json.Unmarshal(data, &b); err != nil {
+		return err
+	}
+	v.b = b
+	return nil
+}
+
+func cloneBytes(b []byte) []byte {
+	c := make([]byte, len(b))
+	copy(c, b)
+	return c
+}
+
+// SetBytes adds a copy of b to the cache as a ByteView, so the caller may
+// reuse b afterwards. It is shorthand for Add(key, NewByteView(b)) and
+// allocates len(b) bytes.
+func (c *Cache) SetBytes(key string, b []byte) {
+	c.Add(key, NewByteView(b))
+}
+
+// GetBytes looks up a value stored as a ByteView and returns a copy of its
+// bytes, which the caller may modify; each call allocates a new slice.
+// It reports false if key is missing or holds some other Value type.
+func (c *Cache) GetBytes(key string) ([]byte, bool) {
+	if v, ok := c.Get(key); ok {
+		if bv, ok := v.(ByteView); ok {
+			return bv.ByteSlice(), true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,33 @@
+package lru
+
+import "testing"
+
+func TestSetGetBytes(t *testing.T) {
+	lru := New(int64(0), nil)
+	b := []byte("1234")
+	lru.SetBytes("key1", b)
+	b[0] = 'x'
+	got, ok := lru.GetBytes("key1")
+	if !ok || string(got) != "1234" {
+		t.Fatalf("SetBytes should store a copy, got %q", got)
+	}
+	got[1] = 'x'
+	if again, _ := lru.GetBytes("key1"); string(again) != "1234" {
+		t.Fatalf("GetBytes should return a copy, got %q", again)
+	}
+	lru.Add("key2", String("5678"))
+	if _, ok := lru.GetBytes("key2"); ok {
+		t.Fatalf("GetBytes should miss for values that are not ByteViews")
+	}
+	if _, ok := lru.GetBytes("missing"); ok {
+		t.Fatalf("GetBytes should miss for missing keys")
+	}
+}
+
+func TestSafeSetGetBytes(t *testing.T) {
+	s := NewSafe(WithCodec(GzipCodec))
+	s.SetBytes("key1", []byte("hello hello hello"))
+	if got, ok := s.GetBytes("key1"); !ok || string(got) != "hello hello hello" {
+		t.Fatalf("GetBytes should decompress, got %q", got)
+	}
+}
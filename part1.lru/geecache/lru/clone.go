@@ -0,0 +1,62 @@
+package lru
+
+import "container/heap"
+
+// Clone returns a new cache with the same limits, TTL settings, admission
+// filter and ghost list, and the same entries in the same LRU order. Values
+// are shared with c, not copied. Hooks that decide what may be stored or
+// evicted, Validate and CanEvict, are carried over; hooks that only report
+// what happened are not, so the clone has no OnEvicted, OnEvictedMeta,
+// OnInsert or OnUpdate callback, no Observer and no event channel.
+func (c *Cache) Clone() *Cache {
+	return c.CloneWith(nil)
+}
+
+// CloneWith is like Clone but passes every value through copyValue, so
+// mutable values can be deep-copied. A nil copyValue shares values.
+func (c *Cache) CloneWith(copyValue func(Value) Value) *Cache {
+	n := &Cache{
+		maxBytes:     c.maxBytes,
+		maxEntries:   c.maxEntries,
+		defaultTTL:   c.defaultTTL,
+		maxAge:       c.maxAge,
+		minRetention: c.minRetention,
+		countHits:    c.countHits,
+		trackAccess:  c.trackAccess,
+		codec:        c.codec,
+		excludeKeys:  c.excludeKeys,
+		ghostSize:    c.ghostSize,
+		ll:           newEntryList(),
+		cache:        make(map[string]*entry, len(c.cache)),
+		Validate:     c.Validate,
+		CanEvict:     c.CanEvict,
+	}
+	n.expireSeq = c.expireSeq
+	if c.admission != nil {
+		n.admission = c.admission.clone()
+	}
+	if c.ghost != nil {
+		n.ghost = c.ghost.clone()
+	}
+	// 从最旧的开始插入，保持相同的访问顺序
+	for old := c.ll.Back(); old != nil; old = c.ll.Prev(old) {
+		kv := *old
+		// 压缩后的值不可变，可以直接共享
+		if copyValue != nil && !kv.compressed {
+			kv.value = copyValue(kv.value)
+			kv.size = n.sizeOf(kv.key, kv.value)
+		}
+		if kv.heapIndex >= 0 {
+			// 保留原来的 expireSeq，过期顺序与 c 一致
+			heap.Push(&n.expiries, &kv)
+		}
+		n.ll.PushFront(&kv)
+		n.cache[kv.key] = &kv
+		n.nbytes += kv.size
+		if kv.tags != nil {
+			kv.tags = append([]string(nil), kv.tags...)
+			n.tag(&kv)
+		}
+	}
+	return n
+}
@@ -0,0 +1,68 @@
+package lru
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClone(t *testing.T) {
+	lru := New(int64(len("k1v1k2v2k3v3")), func(string, Value) {
+		t.Fatalf("clone should not share OnEvicted")
+	})
+	lru.Add("k1", String("v1"))
+	lru.Add("k2", String("v2"))
+	lru.Add("k3", String("v3"))
+	lru.Get("k1")
+
+	keys := make([]string, 0)
+	clone := lru.Clone()
+	clone.OnEvicted = func(key string, value Value) {
+		keys = append(keys, key)
+	}
+	if clone.Len() != 3 || clone.Bytes() != lru.Bytes() {
+		t.Fatalf("clone should have the same contents")
+	}
+	clone.Add("k4", String("v4"))
+	if expect := []string{"k2"}; !reflect.DeepEqual(expect, keys) {
+		t.Fatalf("clone should keep LRU order, evicted %s", keys)
+	}
+	if lru.Len() != 3 {
+		t.Fatalf("changes to the clone should not affect the original")
+	}
+}
+
+func TestCloneWith(t *testing.T) {
+	lru := New(int64(0), nil)
+	v := &mutable{b: []byte("12")}
+	lru.Add("k1", v)
+	clone := lru.CloneWith(func(value Value) Value {
+		m := value.(*mutable)
+		return &mutable{b: append([]byte(nil), m.b...)}
+	})
+	v.b[0] = 'x'
+	if got, _ := clone.Get("k1"); string(got.(*mutable).b) != "12" {
+		t.Fatalf("CloneWith should deep-copy values, got %s", got.(*mutable).b)
+	}
+}
+
+func TestCloneGhostList(t *testing.T) {
+	lru := NewWithOptions(WithMaxEntries(1), WithGhostList(4))
+	lru.Add("k1", String("v1"))
+	lru.Add("k2", String("v2"))
+	clone := lru.Clone()
+	if clone.ghost == nil || clone.ghost.len() != 1 {
+		t.Fatalf("clone should keep the ghost list")
+	}
+	// 克隆中的 k1 记得曾被淘汰，回来后躲过一次淘汰
+	clone.Add("k1", String("v1"))
+	if _, ok := clone.Get("k1"); !ok {
+		t.Fatalf("k1 should be admitted")
+	}
+	clone.Add("k3", String("v3"))
+	if _, ok := clone.GetOpt("k1", false); !ok {
+		t.Fatalf("k1 should be spared once in the clone")
+	}
+	if lru.ghost.len() != 1 {
+		t.Fatalf("changes to the clone should not affect the original ghost list")
+	}
+}
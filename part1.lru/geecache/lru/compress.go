@@ -0,0 +1,92 @@
+package lru
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+)
+
+// Codec compresses and decompresses cached bytes.
+type Codec interface {
+	Compress(b []byte) ([]byte, error)
+	Decompress(b []byte) ([]byte, error)
+}
+
+// GzipCodec compresses with compress/gzip at the default level.
+var GzipCodec Codec = gzipCodec{}
+
+type gzipCodec struct{}
+
+func (gzipCodec) Compress(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(b); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gzipCodec) Decompress(b []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+// CompressedView is an immutable compressed byte value. Len reports the
+// compressed size, which is what the cache budgets for.
+type CompressedView struct {
+	b     []byte
+	codec Codec
+}
+
+// Compress returns b compressed with codec.
+func Compress(b []byte, codec Codec) (CompressedView, error) {
+	cb, err := codec.Compress(b)
+	if err != nil {
+		return CompressedView{}, err
+	}
+	return CompressedView{b: cb, codec: codec}, nil
+}
+
+// Len returns the compressed length.
+func (v CompressedView) Len() int {
+	return len(v.b)
+}
+
+// Decompress returns the original bytes.
+func (v CompressedView) Decompress() ([]byte, error) {
+	return v.codec.Decompress(v.b)
+}
+
+// compress stores a ByteView compressed when the cache has a codec.
+// Values that fail to compress are stored as they are.
+func (c *Cache) compress(value Value) (Value, bool) {
+	bv, ok := value.(ByteView)
+	if !ok {
+		return value, false
+	}
+	cv, err := Compress(bv.b, c.codec)
+	if err != nil {
+		return value, false
+	}
+	return cv, true
+}
+
+// valueOf returns the value the caller stored for kv, decompressing it if
+// the cache compressed it on Add.
+func (c *Cache) valueOf(kv *entry) (Value, bool) {
+	if !kv.compressed {
+		return kv.value, true
+	}
+	b, err := kv.value.(CompressedView).Decompress()
+	if err != nil {
+		return nil, false
+	}
+	return ByteView{b: b}, true
+}
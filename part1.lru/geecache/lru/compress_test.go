@@ -0,0 +1,39 @@
+package lru
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCompressedView(t *testing.T) {
+	data := bytes.Repeat([]byte("geecache "), 100)
+	v, err := Compress(data, GzipCodec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Len() >= len(data) {
+		t.Fatalf("expected compressed length below %d but got %d", len(data), v.Len())
+	}
+	b, err := v.Decompress()
+	if err != nil || !bytes.Equal(b, data) {
+		t.Fatalf("Decompress should return the original bytes")
+	}
+}
+
+func TestWithCodec(t *testing.T) {
+	data := bytes.Repeat([]byte("geecache "), 100)
+	lru := NewWithOptions(WithCodec(GzipCodec))
+	lru.Add("key1", NewByteView(data))
+	lru.Add("key2", String("plain"))
+
+	if lru.Bytes() >= int64(len(data)) {
+		t.Fatalf("compressed size should be budgeted, got %d bytes", lru.Bytes())
+	}
+	v, ok := lru.Get("key1")
+	if !ok || !bytes.Equal(v.(ByteView).ByteSlice(), data) {
+		t.Fatalf("Get should return the decompressed ByteView")
+	}
+	if v, ok := lru.Get("key2"); !ok || string(v.(String)) != "plain" {
+		t.Fatalf("non-ByteView values should be stored unchanged")
+	}
+}
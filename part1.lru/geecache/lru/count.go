@@ -0,0 +1,64 @@
+package lru
+
+// CountLRU is an LRU cache bounded only by its number of entries, for values
+// that have no meaningful size. Values are stored as they are and need not
+// implement Value. It is built on Cache and is not safe for concurrent access.
+type CountLRU struct {
+	lru *Cache
+}
+
+// countValue adapts an arbitrary value to Value; it takes no bytes.
+type countValue struct {
+	v any
+}
+
+func (countValue) Len() int {
+	return 0
+}
+
+// NewCountLRU creates a cache holding at most maxEntries entries, evicting
+// the least recently used one when full. 0 means unlimited. onEvicted is
+// optional and executed when an entry is purged.
+func NewCountLRU(maxEntries int, onEvicted func(key string, value any)) *CountLRU {
+	c := &CountLRU{lru: NewWithOptions(WithMaxEntries(maxEntries))}
+	c.lru.excludeKeys = true
+	if onEvicted != nil {
+		c.lru.OnEvicted = func(key string, value Value) {
+			onEvicted(key, value.(countValue).v)
+		}
+	}
+	return c
+}
+
+// Add adds a value to the cache. Unlike Cache.Add, nil values are stored.
+func (c *CountLRU) Add(key string, value any) {
+	c.lru.add(key, countValue{value}, c.lru.expiry(0))
+}
+
+// Get looks up a key's value.
+func (c *CountLRU) Get(key string) (value any, ok bool) {
+	if v, ok := c.lru.Get(key); ok {
+		return v.(countValue).v, true
+	}
+	return nil, false
+}
+
+// Remove removes key from the cache and reports whether it was present.
+func (c *CountLRU) Remove(key string) bool {
+	return c.lru.Remove(key)
+}
+
+// RemoveOldest removes the least recently used entry.
+func (c *CountLRU) RemoveOldest() {
+	c.lru.RemoveOldest()
+}
+
+// Clear removes every entry, calling onEvicted for each one.
+func (c *CountLRU) Clear() {
+	c.lru.Clear()
+}
+
+// Len returns the number of cache entries.
+func (c *CountLRU) Len() int {
+	return c.lru.Len()
+}
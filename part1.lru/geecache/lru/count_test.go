@@ -0,0 +1,30 @@
+package lru
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCountLRU(t *testing.T) {
+	var evicted []any
+	c := NewCountLRU(2, func(key string, value any) {
+		evicted = append(evicted, key, value)
+	})
+	c.Add("k1", 1)
+	c.Add("k2", []string{"a"})
+	c.Get("k1")
+	c.Add("k3", nil)
+
+	if _, ok := c.Get("k2"); ok || c.Len() != 2 {
+		t.Fatalf("k2 is least recently used and should be evicted")
+	}
+	if v, ok := c.Get("k1"); !ok || v != 1 {
+		t.Fatalf("cache hit k1=1 failed, got %v", v)
+	}
+	if v, ok := c.Get("k3"); !ok || v != nil {
+		t.Fatalf("nil values should be stored, got %v, %v", v, ok)
+	}
+	if want := []any{"k2", []string{"a"}}; !reflect.DeepEqual(evicted, want) {
+		t.Fatalf("unexpected evictions %v", evicted)
+	}
+}
@@ -0,0 +1,57 @@
+package lru
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrNotFound is returned by load-through methods when the loader did not
+	// produce a value for the requested key. Loaders may return it (or wrap
+	// it) themselves to report a missing key without it counting as a
+	// failure.
+	ErrNotFound = errors.New("lru: key not found")
+	// ErrValueTooLarge is returned when an entry alone exceeds maxBytes and
+	// so cannot be cached. Any previous value for the key is removed.
+	ErrValueTooLarge = errors.New("lru: value too large to cache")
+	// ErrRejected is returned when the TinyLFU admission filter keeps a new
+	// key out because it is colder than the entries it would evict. The
+	// value itself is fine, so load-through methods still return it.
+	ErrRejected = errors.New("lru: rejected by admission filter")
+	// ErrNilValue is returned when a nil Value is added. A typed nil pointer
+	// whose Len method handles nil is not affected.
+	ErrNilValue = errors.New("lru: nil value")
+	// ErrLoaderFailed matches, with errors.Is, every *LoaderError.
+	ErrLoaderFailed = errors.New("lru: loader failed")
+)
+
+// LoaderError reports that a loader called by a load-through method failed.
+// errors.Is matches both ErrLoaderFailed and the underlying error.
+type LoaderError struct {
+	Keys []string
+	Err  error
+}
+
+func (e *LoaderError) Error() string {
+	return fmt.Sprintf("lru: loader failed for %s: %v", strings.Join(e.Keys, ", "), e.Err)
+}
+
+// Unwrap returns the loader's error.
+func (e *LoaderError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrLoaderFailed.
+func (e *LoaderError) Is(target error) bool {
+	return target == ErrLoaderFailed
+}
+
+// loaderError wraps err from a loader called for keys. ErrNotFound is passed
+// through unchanged since a missing key is not a loader failure.
+func loaderError(err error, keys ...string) error {
+	if err == nil || errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return &LoaderError{Keys: keys, Err: err}
+}
@@ -0,0 +1,93 @@
+package lru
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestErrValueTooLarge(t *testing.T) {
+	lru := New(int64(len("k1v1")), nil)
+	lru.Add("k1", String("v1"))
+	if err := lru.AddChecked("k2", String("too large")); err != ErrValueTooLarge {
+		t.Fatalf("expected ErrValueTooLarge but got %v", err)
+	}
+	if _, ok := lru.Get("k1"); !ok {
+		t.Fatalf("an oversized value should not flush the cache")
+	}
+}
+
+func TestErrValueTooLargeOverwrite(t *testing.T) {
+	var evicted []string
+	lru := New(int64(len("k1v1")), func(key string, value Value) {
+		evicted = append(evicted, key+"="+string(value.(String)))
+	})
+	lru.Add("k1", String("v1"))
+	if err := lru.AddChecked("k1", String("too large")); err != ErrValueTooLarge {
+		t.Fatalf("expected ErrValueTooLarge but got %v", err)
+	}
+	// 旧值已过时，应当被移除并回调
+	if _, ok := lru.Get("k1"); ok || lru.Len() != 0 || lru.Bytes() != 0 {
+		t.Fatalf("a rejected overwrite should remove the stale value")
+	}
+	if len(evicted) != 1 || evicted[0] != "k1=v1" {
+		t.Fatalf("expected OnEvicted for the stale value, got %v", evicted)
+	}
+}
+
+func TestLoaderErrors(t *testing.T) {
+	errDB := errors.New("db down")
+	lru := New(int64(len("k1v1")), nil)
+
+	_, err := lru.GetOrLoadRelated("k1", func(string) (map[string]Value, error) {
+		return nil, errDB
+	})
+	if !errors.Is(err, ErrLoaderFailed) || !errors.Is(err, errDB) {
+		t.Fatalf("expected a loader failure wrapping errDB, got %v", err)
+	}
+	var le *LoaderError
+	if !errors.As(err, &le) || len(le.Keys) != 1 || le.Keys[0] != "k1" {
+		t.Fatalf("expected a *LoaderError for k1, got %v", err)
+	}
+
+	_, err = lru.GetOrLoadRelated("k1", func(string) (map[string]Value, error) {
+		return nil, ErrNotFound
+	})
+	if err != ErrNotFound {
+		t.Fatalf("ErrNotFound from the loader should pass through, got %v", err)
+	}
+
+	v, err := lru.GetOrLoadRelated("k1", func(string) (map[string]Value, error) {
+		return map[string]Value{"k1": String("too large")}, nil
+	})
+	if err != ErrValueTooLarge || v == nil || lru.Len() != 0 {
+		t.Fatalf("expected the value with ErrValueTooLarge, got %v, %v", v, err)
+	}
+
+	_, err = lru.GetMultiLoad([]string{"k1"}, func([]string) (map[string]Value, error) {
+		return nil, errDB
+	})
+	if !errors.Is(err, ErrLoaderFailed) {
+		t.Fatalf("expected a loader failure, got %v", err)
+	}
+
+	l := NewLoading(NewSafe(), time.Minute, func(string) (Value, error) {
+		return nil, errDB
+	})
+	if _, err = l.Get("k1"); !errors.Is(err, ErrLoaderFailed) || !errors.Is(err, errDB) {
+		t.Fatalf("expected a loader failure wrapping errDB, got %v", err)
+	}
+}
+
+func TestAddNil(t *testing.T) {
+	lru := New(int64(0), nil)
+	lru.Add("k1", String("v1"))
+	if err := lru.AddChecked("k1", nil); err != ErrNilValue {
+		t.Fatalf("expected ErrNilValue but got %v", err)
+	}
+	lru.Add("k2", nil)
+	lru.AddWithTTL("k3", nil, time.Minute)
+	if v, ok := lru.Get("k1"); !ok || string(v.(String)) != "v1" || lru.Len() != 1 {
+		t.Fatalf("nil values should be rejected without touching existing entries")
+	}
+}
@@ -0,0 +1,60 @@
+package lru
+
+// EvictionReason tells why an entry left the cache.
+type EvictionReason int
+
+const (
+	// ReasonCapacity means the entry was evicted to stay within maxBytes.
+	ReasonCapacity EvictionReason = iota
+	// ReasonExpired means the entry's TTL had passed.
+	ReasonExpired
+	// ReasonStale means the entry was older than the cache's MaxAge.
+	ReasonStale
+	// ReasonRemoved means the entry was removed explicitly.
+	ReasonRemoved
+)
+
+func (r EvictionReason) String() string {
+	switch r {
+	case ReasonCapacity:
+		return "capacity"
+	case ReasonExpired:
+		return "expired"
+	case ReasonStale:
+		return "stale"
+	case ReasonRemoved:
+		return "removed"
+	}
+	return "unknown"
+}
+
+// EvictionEvent describes one entry leaving the cache.
+type EvictionEvent struct {
+	Key    string
+	Value  Value
+	Meta   interface{} // set by AddWithMeta, otherwise nil
+	Reason EvictionReason
+}
+
+// eventBuffer is the capacity of the channel returned by Events.
+const eventBuffer = 128
+
+// Events returns a channel that receives an EvictionEvent for every entry
+// removed from the cache. There is a single channel per cache; repeated calls
+// return the same one. The channel is buffered and never blocks the cache:
+// when the buffer is full new events are dropped, so a slow consumer loses
+// events rather than stalling Add.
+func (c *Cache) Events() <-chan EvictionEvent {
+	if c.events == nil {
+		c.events = make(chan EvictionEvent, eventBuffer)
+	}
+	return c.events
+}
+
+func (c *Cache) emit(ev EvictionEvent) {
+	select {
+	case c.events <- ev:
+	default:
+		// 缓冲区已满，直接丢弃，不阻塞缓存操作
+	}
+}
@@ -0,0 +1,37 @@
+package lru
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestEvents(t *testing.T) {
+	lru := New(int64(10), nil)
+	events := lru.Events()
+	lru.Add("key1", String("123456"))
+	lru.Add("k2", String("k2"))
+	lru.Add("k3", String("k3"))
+
+	select {
+	case ev := <-events:
+		if ev.Key != "key1" || string(ev.Value.(String)) != "123456" || ev.Reason != ReasonCapacity {
+			t.Fatalf("unexpected event %+v", ev)
+		}
+	default:
+		t.Fatalf("expected an eviction event")
+	}
+	if lru.Events() != events {
+		t.Fatalf("Events should return the same channel")
+	}
+}
+
+func TestEventsDropWhenFull(t *testing.T) {
+	lru := NewWithOptions(WithMaxEntries(1))
+	events := lru.Events()
+	for i := 0; i < eventBuffer+11; i++ {
+		lru.Add(strconv.Itoa(i), String("v"))
+	}
+	if len(events) != eventBuffer {
+		t.Fatalf("expected %d buffered events but got %d", eventBuffer, len(events))
+	}
+}
@@ -0,0 +1,78 @@
+package lru
+
+import (
+	"container/heap"
+	"time"
+)
+
+// expiryHeap is a min-heap of the entries that have a TTL, ordered by expiry
+// time, so expired entries can be found without scanning the whole cache.
+// Entries expiring at the same time are ordered by when their TTL was set.
+// 每个条目在 heapIndex 中记录自己的下标，更新和删除时可以直接 Fix/Remove。
+type expiryHeap []*entry
+
+func (h expiryHeap) Len() int { return len(h) }
+func (h expiryHeap) Less(i, j int) bool {
+	if h[i].expire.Equal(h[j].expire) {
+		return h[i].expireSeq < h[j].expireSeq
+	}
+	return h[i].expire.Before(h[j].expire)
+}
+
+func (h expiryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].heapIndex = i
+	h[j].heapIndex = j
+}
+
+func (h *expiryHeap) Push(x interface{}) {
+	kv := x.(*entry)
+	kv.heapIndex = len(*h)
+	*h = append(*h, kv)
+}
+
+func (h *expiryHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	kv := old[n-1]
+	old[n-1] = nil
+	kv.heapIndex = -1
+	*h = old[:n-1]
+	return kv
+}
+
+// setExpire changes kv's expiry time and keeps the heap in step with it.
+func (c *Cache) setExpire(kv *entry, expire time.Time) {
+	kv.expire = expire
+	if !expire.IsZero() {
+		c.expireSeq++
+		kv.expireSeq = c.expireSeq
+	}
+	switch {
+	case expire.IsZero():
+		// 永不过期的条目不在堆中
+		if kv.heapIndex >= 0 {
+			heap.Remove(&c.expiries, kv.heapIndex)
+		}
+	case kv.heapIndex >= 0:
+		heap.Fix(&c.expiries, kv.heapIndex)
+	default:
+		heap.Push(&c.expiries, kv)
+	}
+}
+
+// RemoveExpired removes every entry whose TTL has passed and returns how many
+// were removed, earliest expiry first and, for equal expiry times, in the
+// order the TTLs were set. Expired entries are otherwise only dropped when they are
+// looked up; calling RemoveExpired periodically frees their memory sooner.
+// It costs O(k log n) for k expired entries rather than a scan of the cache.
+// Entries past maxAge but not their TTL are left to the usual lazy removal.
+func (c *Cache) RemoveExpired() int {
+	t := now()
+	n := 0
+	for len(c.expiries) > 0 && c.expiries[0].expired(t) {
+		c.removeElement(c.expiries[0], ReasonExpired)
+		n++
+	}
+	return n
+}
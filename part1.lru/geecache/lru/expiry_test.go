@@ -0,0 +1,62 @@
+package lru
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRemoveExpired(t *testing.T) {
+	advance, restore := fakeClock()
+	defer restore()
+	var evicted []string
+	lru := NewWithOptions(WithOnEvicted(func(key string, value Value) {
+		evicted = append(evicted, key)
+	}))
+	lru.AddWithTTL("k1", String("1"), 3*time.Second)
+	lru.AddWithTTL("k2", String("2"), time.Second)
+	lru.AddWithTTL("k3", String("3"), 2*time.Second)
+	lru.Add("k4", String("4"))
+
+	if n := lru.RemoveExpired(); n != 0 {
+		t.Fatalf("nothing has expired yet but removed %d", n)
+	}
+	advance(2 * time.Second)
+	if n := lru.RemoveExpired(); n != 2 || lru.Len() != 2 {
+		t.Fatalf("expected k2 and k3 to be removed, removed %d, left %d", n, lru.Len())
+	}
+	// 按过期时间从早到晚移除
+	if len(evicted) != 2 || evicted[0] != "k2" || evicted[1] != "k3" {
+		t.Fatalf("unexpected eviction order %v", evicted)
+	}
+	advance(time.Hour)
+	if n := lru.RemoveExpired(); n != 1 {
+		t.Fatalf("expected only k1 to be removed, removed %d", n)
+	}
+	if _, ok := lru.Get("k4"); !ok {
+		t.Fatalf("k4 has no TTL and should be kept")
+	}
+}
+
+func TestRemoveExpiredAfterUpdate(t *testing.T) {
+	advance, restore := fakeClock()
+	defer restore()
+	lru := New(int64(0), nil)
+	lru.AddWithTTL("k1", String("1"), time.Second)
+	lru.AddWithTTL("k2", String("2"), time.Second)
+	// 更新后 k1 的过期时间推后，k2 变为永不过期
+	lru.AddWithTTL("k1", String("1"), time.Minute)
+	lru.Add("k2", String("2"))
+	lru.Remove("k2")
+	lru.AddWithTTL("k3", String("3"), time.Second)
+
+	advance(time.Second)
+	if n := lru.RemoveExpired(); n != 1 {
+		t.Fatalf("expected only k3 to be removed, removed %d", n)
+	}
+	if _, ok := lru.Get("k1"); !ok {
+		t.Fatalf("k1 was refreshed and should be kept")
+	}
+	if len(lru.expiries) != 1 || lru.expiries[0].key != "k1" {
+		t.Fatalf("heap out of step with the cache: %d entries", len(lru.expiries))
+	}
+}
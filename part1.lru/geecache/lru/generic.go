@@ -0,0 +1,129 @@
+package lru
+
+import (
+	"strconv"
+	"time"
+)
+
+// TTLCache is a typed LRU cache with per-key TTL, built on Cache so it
+// shares the same eviction, byte/count limits and expiry behaviour.
+// It is not safe for concurrent access.
+type TTLCache[K comparable, V any] struct {
+	// Size reports how many bytes a value takes for the byte limit.
+	// If nil, values take no bytes and only WithMaxEntries bounds the cache.
+	Size func(value V) int
+	// optional and executed when an entry is purged.
+	OnEvicted func(key K, value V)
+
+	lru  *Cache
+	ids  map[K]string // 用户 key 到内部字符串 key 的映射
+	keys map[string]K // 内部 key 到用户 key 的反向映射
+	next uint64
+}
+
+// typedValue adapts a V to Value using the size measured on Add.
+type typedValue[V any] struct {
+	v    V
+	size int
+}
+
+func (t typedValue[V]) Len() int {
+	return t.size
+}
+
+// NewTTLCache creates a TTLCache configured by opts, e.g. WithMaxBytes,
+// WithMaxEntries, WithDefaultTTL or WithMaxAge. The untyped WithOnEvicted,
+// WithOnInsert, WithOnUpdate, WithValidate and WithCanEvict options are
+// ignored; set the OnEvicted field instead. WithTinyLFU and WithGhostList
+// are ignored too: they remember keys across evictions, but a key gets a
+// fresh internal id each time it is stored again, so they would never
+// recognise it.
+func NewTTLCache[K comparable, V any](opts ...Option) *TTLCache[K, V] {
+	c := &TTLCache[K, V]{
+		lru:  NewWithOptions(opts...),
+		ids:  make(map[K]string),
+		keys: make(map[string]K),
+	}
+	c.lru.excludeKeys = true
+	c.lru.Validate = nil
+	c.lru.CanEvict = nil
+	c.lru.OnInsert = nil
+	c.lru.OnUpdate = nil
+	c.lru.OnEvicted = c.evicted
+	// 内部 id 在 key 被淘汰后不再复用，依赖历史的策略无法生效
+	c.lru.admission = nil
+	c.lru.ghost = nil
+	return c
+}
+
+func (c *TTLCache[K, V]) evicted(id string, value Value) {
+	key := c.keys[id]
+	delete(c.keys, id)
+	delete(c.ids, key)
+	if c.OnEvicted != nil {
+		c.OnEvicted(key, value.(typedValue[V]).v)
+	}
+}
+
+// Add adds a value that expires after the default TTL, if any.
+func (c *TTLCache[K, V]) Add(key K, value V) {
+	c.AddWithTTL(key, value, 0)
+}
+
+// AddWithTTL adds a value that expires after ttl, with the same meaning of
+// zero and NoExpiration as Cache.AddWithTTL.
+func (c *TTLCache[K, V]) AddWithTTL(key K, value V, ttl time.Duration) {
+	id, ok := c.ids[key]
+	if !ok {
+		c.next++
+		id = strconv.FormatUint(c.next, 36)
+		c.ids[key] = id
+		c.keys[id] = key
+	}
+	size := 0
+	if c.Size != nil {
+		size = c.Size(value)
+	}
+	kv, _ := c.lru.addChecked(id, typedValue[V]{v: value, size: size}, c.lru.expiry(ttl))
+	if kv == nil && !ok {
+		// 没有被存下（例如被准入策略拒绝或立即被淘汰），清理映射
+		delete(c.ids, key)
+		delete(c.keys, id)
+	}
+}
+
+// Get look ups a key's value
+func (c *TTLCache[K, V]) Get(key K) (value V, ok bool) {
+	value, _, ok = c.GetWithExpiry(key)
+	return
+}
+
+// GetWithExpiry is like Get but also returns the entry's expiry time; a zero
+// time.Time means it never expires.
+func (c *TTLCache[K, V]) GetWithExpiry(key K) (value V, expiresAt time.Time, ok bool) {
+	id, found := c.ids[key]
+	if !found {
+		return
+	}
+	v, expiresAt, ok := c.lru.GetWithExpiry(id)
+	if !ok {
+		return value, time.Time{}, false
+	}
+	return v.(typedValue[V]).v, expiresAt, true
+}
+
+// Remove removes key from the cache and reports whether it was present.
+func (c *TTLCache[K, V]) Remove(key K) bool {
+	id, ok := c.ids[key]
+	return ok && c.lru.Remove(id)
+}
+
+// Len the number of cache entries
+func (c *TTLCache[K, V]) Len() int {
+	return c.lru.Len()
+}
+
+// Bytes returns the total Size of the cached values.
+func (c *TTLCache[K, V]) Bytes() int64 {
+	return c.lru.Bytes()
+}
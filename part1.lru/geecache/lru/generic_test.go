@@ -0,0 +1,89 @@
+package lru
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type point struct{ x, y int }
+
+func TestTTLCache(t *testing.T) {
+	advance, restore := fakeClock()
+	defer restore()
+	c := NewTTLCache[point, []byte](WithMaxBytes(6), WithDefaultTTL(time.Minute))
+	c.Size = func(v []byte) int { return len(v) }
+	evicted := make([]point, 0)
+	c.OnEvicted = func(key point, value []byte) {
+		evicted = append(evicted, key)
+	}
+
+	c.Add(point{1, 1}, []byte("aaa"))
+	c.AddWithTTL(point{2, 2}, []byte("bbb"), NoExpiration)
+	if v, ok := c.Get(point{1, 1}); !ok || string(v) != "aaa" {
+		t.Fatalf("cache hit {1 1}=aaa failed")
+	}
+	c.Add(point{3, 3}, []byte("ccc"))
+	if expect := []point{{2, 2}}; !reflect.DeepEqual(expect, evicted) || c.Bytes() != 6 {
+		t.Fatalf("expected %v to be evicted by size but got %v", expect, evicted)
+	}
+
+	advance(time.Minute)
+	if _, ok := c.Get(point{1, 1}); ok {
+		t.Fatalf("{1 1} should expire after the default TTL")
+	}
+	if !c.Remove(point{3, 3}) || c.Len() != 0 || len(c.ids) != 0 || len(c.keys) != 0 {
+		t.Fatalf("key mappings should be cleaned up with the entries")
+	}
+}
+
+func TestTTLCacheMaxEntries(t *testing.T) {
+	c := NewTTLCache[int, string](WithMaxEntries(2))
+	c.Add(1, "a")
+	c.Add(2, "b")
+	c.Add(1, "aa")
+	c.Add(3, "c")
+	if _, ok := c.Get(2); ok || c.Len() != 2 {
+		t.Fatalf("expected key 2 to be evicted")
+	}
+	if v, _ := c.Get(1); v != "aa" {
+		t.Fatalf("expected updated value aa but got %q", v)
+	}
+}
+
+func BenchmarkCacheAddGet(b *testing.B) {
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+	lru := NewWithOptions(WithMaxEntries(512))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		k := keys[i%len(keys)]
+		lru.Add(k, String(k))
+		lru.Get(k)
+	}
+}
+
+func BenchmarkTTLCacheAddGet(b *testing.B) {
+	c := NewTTLCache[int, string](WithMaxEntries(512))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		k := i % 1024
+		c.Add(k, "v")
+		c.Get(k)
+	}
+}
+
+func TestTTLCacheIgnoresHistoryOptions(t *testing.T) {
+	c := NewTTLCache[int, string](WithMaxEntries(1), WithTinyLFU(100), WithGhostList(4))
+	c.Add(1, "a")
+	for i := 0; i < 3; i++ {
+		c.Add(2, "b")
+	}
+	// 没有准入过滤，新 key 照常挤掉旧 key
+	if _, ok := c.Get(2); !ok || c.Len() != 1 {
+		t.Fatalf("a repeatedly added key should be stored")
+	}
+}
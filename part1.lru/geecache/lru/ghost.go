@@ -0,0 +1,62 @@
+package lru
+
+import "container/list"
+
+// defaultGhostEntries is the ghost list size for caches bounded only by
+// bytes, where the number of entries is not known up front.
+const defaultGhostEntries = 128
+
+// ghostList remembers the keys of recently evicted entries, without their
+// values. A key that comes back while still remembered was evicted too
+// eagerly, so the new entry is given a reprieve from its next eviction.
+type ghostList struct {
+	size int
+	ll   *list.List
+	keys map[string]*list.Element
+}
+
+func newGhostList(size int) *ghostList {
+	return &ghostList{
+		size: size,
+		ll:   list.New(),
+		keys: make(map[string]*list.Element),
+	}
+}
+
+// add remembers key, forgetting the oldest key when the list is full.
+func (g *ghostList) add(key string) {
+	if ele, ok := g.keys[key]; ok {
+		g.ll.MoveToFront(ele)
+		return
+	}
+	g.keys[key] = g.ll.PushFront(key)
+	if g.ll.Len() > g.size {
+		oldest := g.ll.Back()
+		g.ll.Remove(oldest)
+		delete(g.keys, oldest.Value.(string))
+	}
+}
+
+// take reports whether key was remembered and forgets it.
+func (g *ghostList) take(key string) bool {
+	ele, ok := g.keys[key]
+	if ok {
+		g.ll.Remove(ele)
+		delete(g.keys, key)
+	}
+	return ok
+}
+
+// clone returns a copy of g that remembers the same keys in the same order.
+func (g *ghostList) clone() *ghostList {
+	n := newGhostList(g.size)
+	for ele := g.ll.Back(); ele != nil; ele = ele.Prev() {
+		key := ele.Value.(string)
+		n.keys[key] = n.ll.PushFront(key)
+	}
+	return n
+}
+
+func (g *ghostList) len() int {
+	return g.ll.Len()
+}
@@ -0,0 +1,39 @@
+package lru
+
+import "testing"
+
+func TestGhostList(t *testing.T) {
+	lru := NewWithOptions(WithMaxEntries(2), WithGhostList(4))
+	lru.Add("k1", String("v1"))
+	lru.Add("k2", String("v2"))
+	lru.Add("k3", String("v3")) // 淘汰 k1，记入幽灵列表
+	lru.Add("k1", String("v1")) // k1 回来，淘汰 k2
+	if lru.ghost.len() != 1 {
+		t.Fatalf("expected k2 in the ghost list, got %d keys", lru.ghost.len())
+	}
+	// k1 是最久未访问的，但有一次豁免，应该淘汰 k3
+	lru.Get("k3")
+	lru.Add("k4", String("v4"))
+	if _, ok := lru.Get("k1"); !ok {
+		t.Fatalf("k1 returned from the ghost list and should be spared once")
+	}
+	if _, ok := lru.Get("k3"); ok {
+		t.Fatalf("k3 should be evicted instead of k1")
+	}
+}
+
+func TestGhostListDefaultSize(t *testing.T) {
+	if lru := NewWithOptions(WithMaxEntries(100), WithGhostList(0)); lru.ghostSize != 25 {
+		t.Fatalf("expected default ghost size 25 but got %d", lru.ghostSize)
+	}
+	if lru := NewWithOptions(WithGhostList(0)); lru.ghostSize != defaultGhostEntries {
+		t.Fatalf("expected default ghost size %d but got %d", defaultGhostEntries, lru.ghostSize)
+	}
+	g := newGhostList(2)
+	g.add("a")
+	g.add("b")
+	g.add("c")
+	if g.take("a") || !g.take("c") || g.len() != 1 {
+		t.Fatalf("ghost list should forget its oldest key when full")
+	}
+}
@@ -0,0 +1,50 @@
+package lru
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// KeyCount is a key together with the number of Get hits it has served.
+type KeyCount struct {
+	Key   string
+	Count int64
+}
+
+// keyCountHeap is a min-heap on Count used to keep the top N.
+type keyCountHeap []KeyCount
+
+func (h keyCountHeap) Len() int            { return len(h) }
+func (h keyCountHeap) Less(i, j int) bool  { return h[i].Count < h[j].Count }
+func (h keyCountHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *keyCountHeap) Push(x interface{}) { *h = append(*h, x.(KeyCount)) }
+func (h *keyCountHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// HotKeys returns up to n cached keys with the most Get hits, most hit
+// first. Counts are only kept when the cache was built WithHitCounts;
+// otherwise HotKeys returns nil. It scans every entry, O(len * log n).
+func (c *Cache) HotKeys(n int) []KeyCount {
+	if !c.countHits || n <= 0 {
+		return nil
+	}
+	h := make(keyCountHeap, 0, n)
+	for kv := c.ll.Front(); kv != nil; kv = c.ll.Next(kv) {
+		if kv.hits == 0 {
+			continue
+		}
+		if len(h) < n {
+			heap.Push(&h, KeyCount{kv.key, kv.hits})
+		} else if kv.hits > h[0].Count {
+			// 比堆顶（当前 top n 的最小值）大，替换堆顶
+			h[0] = KeyCount{kv.key, kv.hits}
+			heap.Fix(&h, 0)
+		}
+	}
+	sort.Slice(h, func(i, j int) bool { return h[i].Count > h[j].Count })
+	return h
+}
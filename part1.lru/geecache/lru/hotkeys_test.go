@@ -0,0 +1,29 @@
+package lru
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHotKeys(t *testing.T) {
+	lru := NewWithOptions(WithHitCounts())
+	for _, k := range []string{"a", "b", "c", "d"} {
+		lru.Add(k, String("v"))
+	}
+	hits := map[string]int{"a": 1, "b": 5, "c": 3}
+	for k, n := range hits {
+		for i := 0; i < n; i++ {
+			lru.Get(k)
+		}
+	}
+	expect := []KeyCount{{"b", 5}, {"c", 3}}
+	if got := lru.HotKeys(2); !reflect.DeepEqual(expect, got) {
+		t.Fatalf("expected %v but got %v", expect, got)
+	}
+	if got := lru.HotKeys(10); len(got) != 3 {
+		t.Fatalf("keys without hits should be skipped, got %v", got)
+	}
+	if New(int64(0), nil).HotKeys(2) != nil {
+		t.Fatalf("HotKeys should be nil without WithHitCounts")
+	}
+}
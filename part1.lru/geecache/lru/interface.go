@@ -0,0 +1,50 @@
+package lru
+
+// Interface is the method set shared by every cache in this package, so
+// callers can depend on it, inject fakes in tests, or disable caching with
+// NullCache without nil checks at each call site.
+type Interface interface {
+	Add(key string, value Value)
+	Get(key string) (value Value, ok bool)
+	Remove(key string) bool
+	Clear()
+	Len() int
+	Bytes() int64
+}
+
+var (
+	_ Interface = (*Cache)(nil)
+	_ Interface = (*SafeCache)(nil)
+	_ Interface = (*Tiered)(nil)
+	_ Interface = NullCache{}
+)
+
+// NullCache stores nothing: every Add is discarded and every Get misses.
+// It holds no state and is therefore safe for concurrent use.
+type NullCache struct{}
+
+// Add discards the value.
+func (NullCache) Add(key string, value Value) {}
+
+// Get always misses.
+func (NullCache) Get(key string) (value Value, ok bool) {
+	return
+}
+
+// Remove always reports that key was not present.
+func (NullCache) Remove(key string) bool {
+	return false
+}
+
+// Clear does nothing.
+func (NullCache) Clear() {}
+
+// Len is always 0.
+func (NullCache) Len() int {
+	return 0
+}
+
+// Bytes is always 0.
+func (NullCache) Bytes() int64 {
+	return 0
+}
@@ -0,0 +1,42 @@
+package lru
+
+import "testing"
+
+func TestRemove(t *testing.T) {
+	keys := make([]string, 0)
+	lru := New(int64(0), func(key string, value Value) {
+		keys = append(keys, key)
+	})
+	lru.Add("key1", String("1234"))
+	if !lru.Remove("key1") || lru.Remove("key1") {
+		t.Fatalf("Remove key1 should succeed exactly once")
+	}
+	if _, ok := lru.Get("key1"); ok || lru.Len() != 0 || lru.Bytes() != 0 {
+		t.Fatalf("key1 should be gone after Remove")
+	}
+	if len(keys) != 1 || keys[0] != "key1" {
+		t.Fatalf("Remove should call OnEvicted, got %s", keys)
+	}
+}
+
+func TestNullCache(t *testing.T) {
+	var cache Interface = NullCache{}
+	cache.Add("key1", String("1234"))
+	if _, ok := cache.Get("key1"); ok || cache.Len() != 0 || cache.Remove("key1") {
+		t.Fatalf("NullCache should store nothing")
+	}
+}
+
+func TestClear(t *testing.T) {
+	for name, cache := range map[string]Interface{
+		"Cache":     New(int64(0), nil),
+		"SafeCache": NewSafe(),
+	} {
+		cache.Add("key1", String("1234"))
+		cache.Add("key2", String("5678"))
+		cache.Clear()
+		if _, ok := cache.Get("key1"); ok || cache.Len() != 0 || cache.Bytes() != 0 {
+			t.Fatalf("%s: Clear should remove every entry", name)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package lru
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// checkInvariants verifies that the cache's internal structures agree with
+// each other: the map and the list hold the same entries, the byte count
+// matches the entries' sizes, and the expiry heap and tag index cover
+// exactly the entries that need them.
+func (c *Cache) checkInvariants() error {
+	if c.ll.Len() != len(c.cache) || c.Len() != len(c.cache) {
+		return fmt.Errorf("list has %d entries, map has %d, Len reports %d", c.ll.Len(), len(c.cache), c.Len())
+	}
+	var nbytes int64
+	seen := make(map[string]bool, len(c.cache))
+	timed := 0
+	for kv := c.ll.Front(); kv != nil; kv = c.ll.Next(kv) {
+		if kv.next.prev != kv || kv.prev.next != kv {
+			return fmt.Errorf("links around %q are broken", kv.key)
+		}
+		if seen[kv.key] {
+			return fmt.Errorf("key %q is in the list twice", kv.key)
+		}
+		seen[kv.key] = true
+		if c.cache[kv.key] != kv {
+			return fmt.Errorf("map entry for %q does not point at its list node", kv.key)
+		}
+		if kv.size != c.sizeOf(kv.key, kv.value) {
+			return fmt.Errorf("size of %q is %d, want %d", kv.key, kv.size, c.sizeOf(kv.key, kv.value))
+		}
+		nbytes += kv.size
+		if kv.expire.IsZero() != (kv.heapIndex < 0) {
+			return fmt.Errorf("%q has expiry %v but heap index %d", kv.key, kv.expire, kv.heapIndex)
+		}
+		if kv.heapIndex >= 0 {
+			timed++
+			if kv.heapIndex >= len(c.expiries) || c.expiries[kv.heapIndex] != kv {
+				return fmt.Errorf("heap index %d of %q is stale", kv.heapIndex, kv.key)
+			}
+		}
+		for _, tag := range kv.tags {
+			if _, ok := c.tags[tag][kv.key]; !ok {
+				return fmt.Errorf("%q is missing from the index of tag %q", kv.key, tag)
+			}
+		}
+	}
+	if len(seen) != c.ll.Len() {
+		return fmt.Errorf("walked %d entries, list reports %d", len(seen), c.ll.Len())
+	}
+	if nbytes != c.nbytes || c.Bytes() != nbytes {
+		return fmt.Errorf("nbytes is %d, entries add up to %d", c.nbytes, nbytes)
+	}
+	if timed != len(c.expiries) {
+		return fmt.Errorf("heap has %d entries, %d entries have a TTL", len(c.expiries), timed)
+	}
+	for i := 1; i < len(c.expiries); i++ {
+		if c.expiries.Less(i, (i-1)/2) {
+			return fmt.Errorf("heap order violated at %d", i)
+		}
+	}
+	for tag, keys := range c.tags {
+		if len(keys) == 0 {
+			return fmt.Errorf("tag %q has an empty index", tag)
+		}
+		for key := range keys {
+			if !seen[key] {
+				return fmt.Errorf("tag %q indexes missing key %q", tag, key)
+			}
+		}
+	}
+	return nil
+}
+
+// FuzzCacheInvariants drives random sequences of operations, two bytes per
+// operation, and checks the invariants after each one.
+func FuzzCacheInvariants(f *testing.F) {
+	f.Add([]byte{0, 1, 0, 2, 1, 1, 2, 3, 3, 0})
+	f.Add([]byte{4, 5, 5, 5, 6, 0, 7, 1, 8, 2, 9, 3})
+	f.Add([]byte{0, 0, 0, 8, 0, 16, 10, 0, 11, 1, 12, 2})
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		advance, restore := fakeClock()
+		defer restore()
+		lru := NewWithOptions(WithMaxBytes(64), WithMaxEntries(6), WithGhostList(4))
+		for i := 0; i+1 < len(ops); i += 2 {
+			op, arg := ops[i]%13, ops[i+1]
+			key := fmt.Sprintf("k%d", arg%8)
+			value := String(make([]byte, arg%16))
+			switch op {
+			case 0:
+				lru.Add(key, value)
+			case 1:
+				lru.Get(key)
+			case 2:
+				lru.Remove(key)
+			case 3:
+				lru.RemoveOldest()
+			case 4:
+				lru.AddWithTTL(key, value, time.Duration(arg%4)*time.Second)
+			case 5:
+				advance(time.Second)
+			case 6:
+				lru.RemoveExpired()
+			case 7:
+				lru.AddWithTags(key, value, fmt.Sprintf("t%d", arg%3))
+			case 8:
+				lru.InvalidateTag(fmt.Sprintf("t%d", arg%3))
+			case 9:
+				lru.UpdateTTL(key, time.Duration(arg%4)*time.Second)
+			case 10:
+				lru.Pin(key)
+			case 11:
+				lru.Unpin(key)
+			case 12:
+				lru.Trim(int64(arg % 64))
+			}
+			if err := lru.checkInvariants(); err != nil {
+				t.Fatalf("after op %d (%d, %d): %v", i/2, op, arg, err)
+			}
+		}
+	})
+}
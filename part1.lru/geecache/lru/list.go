@@ -0,0 +1,89 @@
+package lru
+
+// entryList is an intrusive doubly linked list of entries. It replaces
+// container/list: the links live in entry itself, so there is no separate
+// element to allocate and no interface{} to type-assert on every access.
+// 和 container/list 一样使用哨兵节点，root.next 为队尾（最近访问），
+// root.prev 为队首（最久未访问）。
+type entryList struct {
+	root entry
+	len  int
+}
+
+func newEntryList() *entryList {
+	l := &entryList{}
+	l.root.next = &l.root
+	l.root.prev = &l.root
+	return l
+}
+
+// Len returns the number of entries in the list.
+func (l *entryList) Len() int {
+	return l.len
+}
+
+// Front returns the most recently used entry, or nil if the list is empty.
+func (l *entryList) Front() *entry {
+	if l.len == 0 {
+		return nil
+	}
+	return l.root.next
+}
+
+// Back returns the least recently used entry, or nil if the list is empty.
+func (l *entryList) Back() *entry {
+	if l.len == 0 {
+		return nil
+	}
+	return l.root.prev
+}
+
+// Next returns the entry after e towards the back, or nil.
+func (l *entryList) Next(e *entry) *entry {
+	if n := e.next; n != &l.root {
+		return n
+	}
+	return nil
+}
+
+// Prev returns the entry before e towards the front, or nil.
+func (l *entryList) Prev(e *entry) *entry {
+	if p := e.prev; p != &l.root {
+		return p
+	}
+	return nil
+}
+
+// insert links e after at.
+func (l *entryList) insert(e, at *entry) {
+	e.prev = at
+	e.next = at.next
+	e.prev.next = e
+	e.next.prev = e
+	l.len++
+}
+
+// PushFront inserts e at the front of the list.
+func (l *entryList) PushFront(e *entry) {
+	l.insert(e, &l.root)
+}
+
+// Remove unlinks e, which must be in the list.
+func (l *entryList) Remove(e *entry) {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	e.next = nil // 避免悬挂指针，帮助 GC
+	e.prev = nil
+	l.len--
+}
+
+// MoveToFront moves e, which must be in the list, to the front.
+func (l *entryList) MoveToFront(e *entry) {
+	if l.root.next == e {
+		return
+	}
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	l.len--
+	l.insert(e, &l.root)
+}
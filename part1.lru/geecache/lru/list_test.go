@@ -0,0 +1,42 @@
+package lru
+
+import (
+	"reflect"
+	"testing"
+)
+
+func listKeys(l *entryList) []string {
+	keys := make([]string, 0, l.Len())
+	for e := l.Front(); e != nil; e = l.Next(e) {
+		keys = append(keys, e.key)
+	}
+	return keys
+}
+
+func TestEntryList(t *testing.T) {
+	l := newEntryList()
+	if l.Front() != nil || l.Back() != nil {
+		t.Fatalf("an empty list has no front or back")
+	}
+	a, b, c := &entry{key: "a"}, &entry{key: "b"}, &entry{key: "c"}
+	l.PushFront(a)
+	l.PushFront(b)
+	l.PushFront(c)
+	if got := listKeys(l); !reflect.DeepEqual(got, []string{"c", "b", "a"}) {
+		t.Fatalf("unexpected order %v", got)
+	}
+	l.MoveToFront(a)
+	l.MoveToFront(a)
+	if got := listKeys(l); !reflect.DeepEqual(got, []string{"a", "c", "b"}) {
+		t.Fatalf("unexpected order after MoveToFront %v", got)
+	}
+	l.Remove(c)
+	if l.Len() != 2 || l.Back() != b || l.Prev(b) != a || l.Prev(a) != nil || l.Next(b) != nil {
+		t.Fatalf("unexpected links after Remove: %v", listKeys(l))
+	}
+	l.Remove(a)
+	l.Remove(b)
+	if l.Len() != 0 || l.Front() != nil {
+		t.Fatalf("list should be empty")
+	}
+}
@@ -0,0 +1,124 @@
+package lru
+
+import (
+	"sync"
+	"time"
+)
+
+// LoadingCache is a read-through cache: misses are filled by a loader and
+// every loaded value is stored with the same TTL.
+//
+// With RefreshAhead set, a hit on an entry that is within the last
+// RefreshAhead fraction of its TTL schedules a background reload while the
+// current, still fresh value is returned, so hot keys never take the slow
+// path. At most one refresh per key is in flight. Call Close to stop
+// scheduling refreshes and wait for those in flight.
+type LoadingCache struct {
+	cache  *SafeCache
+	loader func(key string) (Value, error)
+	ttl    time.Duration
+	// RefreshAhead is the fraction of the TTL, in (0, 1), before expiry at
+	// which a hit triggers a background refresh. 0 disables refresh-ahead.
+	// It must be set before the cache is used.
+	RefreshAhead float64
+
+	mu         sync.Mutex
+	refreshing map[string]bool // 正在后台刷新的 key
+	refreshes  sync.WaitGroup
+	closed     bool // Close 之后不再发起后台刷新
+}
+
+// NewLoading creates a LoadingCache storing loaded values in cache for ttl.
+func NewLoading(cache *SafeCache, ttl time.Duration, loader func(key string) (Value, error)) *LoadingCache {
+	return &LoadingCache{
+		cache:      cache,
+		loader:     loader,
+		ttl:        ttl,
+		refreshing: make(map[string]bool),
+	}
+}
+
+// Get returns the cached value for key, calling the loader on a miss.
+// Loader failures are returned as a *LoaderError; a loader may return
+// ErrNotFound for a missing key. A loaded value too large to cache is
+// returned along with ErrValueTooLarge. A nil value yields ErrNilValue and a
+// value rejected by the cache's Validate hook yields that error; neither
+// value is returned. A value the admission filter keeps out is returned
+// with a nil error, just not cached.
+func (l *LoadingCache) Get(key string) (Value, error) {
+	if v, expiresAt, ok := l.cache.GetWithExpiry(key); ok {
+		if l.shouldRefresh(expiresAt) {
+			l.refresh(key)
+		}
+		return v, nil
+	}
+	return l.load(key)
+}
+
+func (l *LoadingCache) load(key string) (Value, error) {
+	start := time.Now()
+	v, err := l.loader(key)
+	if o := l.cache.lru.observer; o != nil {
+		o.OnLoad(key, time.Since(start), err)
+	}
+	if err != nil {
+		return nil, loaderError(err, key)
+	}
+	switch err := l.cache.addWithTTLChecked(key, v, l.ttl); err {
+	case nil, ErrRejected:
+		// 被准入策略拒绝只是没有缓存，对调用方不算错误
+		return v, nil
+	case ErrValueTooLarge:
+		// 值本身没问题，只是缓存放不下
+		return v, err
+	default:
+		return nil, err
+	}
+}
+
+// shouldRefresh reports whether an entry expiring at expiresAt has entered
+// the refresh-ahead window.
+func (l *LoadingCache) shouldRefresh(expiresAt time.Time) bool {
+	if l.RefreshAhead <= 0 || l.ttl <= 0 || expiresAt.IsZero() {
+		return false
+	}
+	window := time.Duration(l.RefreshAhead * float64(l.ttl))
+	return expiresAt.Sub(now()) <= window
+}
+
+// refresh reloads key in the background unless a refresh is already running.
+func (l *LoadingCache) refresh(key string) {
+	l.mu.Lock()
+	if l.closed || l.refreshing[key] {
+		l.mu.Unlock()
+		return
+	}
+	l.refreshing[key] = true
+	// 在锁内计数，Close 看到 closed 之前启动的刷新都会被等待
+	l.refreshes.Add(1)
+	l.mu.Unlock()
+
+	go func() {
+		defer l.refreshes.Done()
+		// 刷新失败时保留旧值，等它自然过期
+		l.load(key)
+		l.mu.Lock()
+		delete(l.refreshing, key)
+		l.mu.Unlock()
+	}()
+}
+
+// Wait blocks until the background refreshes started so far have finished.
+// Refreshes triggered by Gets running concurrently may not be waited for.
+func (l *LoadingCache) Wait() {
+	l.refreshes.Wait()
+}
+
+// Close stops scheduling background refreshes and waits for those in
+// flight. Get keeps working after Close, loading misses synchronously.
+func (l *LoadingCache) Close() {
+	l.mu.Lock()
+	l.closed = true
+	l.mu.Unlock()
+	l.refreshes.Wait()
+}
@@ -0,0 +1,104 @@
+package lru
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoadingGet(t *testing.T) {
+	var loads int32
+	l := NewLoading(NewSafe(), time.Minute, func(key string) (Value, error) {
+		atomic.AddInt32(&loads, 1)
+		if key == "missing" {
+			return nil, errors.New("not found")
+		}
+		return String("v-" + key), nil
+	})
+	for i := 0; i < 3; i++ {
+		if v, err := l.Get("k"); err != nil || string(v.(String)) != "v-k" {
+			t.Fatalf("unexpected %v, %v", v, err)
+		}
+	}
+	if _, err := l.Get("missing"); err == nil {
+		t.Fatalf("expected loader error")
+	}
+	if n := atomic.LoadInt32(&loads); n != 2 {
+		t.Fatalf("expected 2 loads but got %d", n)
+	}
+}
+
+func TestRefreshAhead(t *testing.T) {
+	advance, restore := fakeClock()
+	defer restore()
+	var loads int32
+	l := NewLoading(NewSafe(), 10*time.Second, func(key string) (Value, error) {
+		n := atomic.AddInt32(&loads, 1)
+		return String(string(rune('0' + n))), nil
+	})
+	l.RefreshAhead = 0.2
+	l.Get("k")
+
+	// 还没进入刷新窗口
+	advance(7 * time.Second)
+	l.Get("k")
+	l.Wait()
+	if n := atomic.LoadInt32(&loads); n != 1 {
+		t.Fatalf("expected no refresh yet but got %d loads", n)
+	}
+
+	// 进入最后 20% 的窗口：返回旧值并在后台刷新
+	advance(time.Second)
+	if v, _ := l.Get("k"); string(v.(String)) != "1" {
+		t.Fatalf("refresh-ahead should return the current value, got %v", v)
+	}
+	l.Wait()
+	if v, _ := l.Get("k"); string(v.(String)) != "2" {
+		t.Fatalf("expected refreshed value 2 but got %v", v)
+	}
+}
+
+func TestLoadingRejectedValue(t *testing.T) {
+	errOdd := errors.New("odd value")
+	cache := NewSafe(WithValidate(func(key string, value Value) error {
+		if value.Len()%2 == 1 {
+			return errOdd
+		}
+		return nil
+	}))
+	l := NewLoading(cache, time.Minute, func(key string) (Value, error) {
+		if key == "nil" {
+			return nil, nil
+		}
+		return String(key), nil
+	})
+	if v, err := l.Get("nil"); v != nil || err != ErrNilValue {
+		t.Fatalf("a nil value should yield ErrNilValue, got %v, %v", v, err)
+	}
+	if v, err := l.Get("odd"); v != nil || err != errOdd {
+		t.Fatalf("a rejected value should yield the Validate error, got %v, %v", v, err)
+	}
+	if v, err := l.Get("even"); err != nil || string(v.(String)) != "even" {
+		t.Fatalf("unexpected %v, %v", v, err)
+	}
+}
+
+func TestLoadingClose(t *testing.T) {
+	advance, restore := fakeClock()
+	defer restore()
+	var loads int32
+	l := NewLoading(NewSafe(), 10*time.Second, func(key string) (Value, error) {
+		atomic.AddInt32(&loads, 1)
+		return String("v"), nil
+	})
+	l.RefreshAhead = 0.5
+	l.Get("k")
+	l.Close()
+	advance(6 * time.Second)
+	l.Get("k")
+	l.Wait()
+	if n := atomic.LoadInt32(&loads); n != 1 {
+		t.Fatalf("no refresh should start after Close, got %d loads", n)
+	}
+}
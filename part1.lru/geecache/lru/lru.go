@@ -1,22 +1,85 @@
 package lru
 
-import "container/list"
+import (
+	"container/heap"
+	"time"
+)
 
 // Cache is a LRU cache. It is not safe for concurrent access.
+//
+// Eviction order is deterministic and depends only on the order of
+// operations, never on timing: capacity evictions take the least recently
+// used entry first, so entries added in sequence, e.g. by Load, leave in the
+// order they were added. When minRetention makes some entries ineligible,
+// the oldest eligible entry in that same order is chosen.
 type Cache struct {
-	maxBytes int64                    // 允许使用的最大内存
-	nbytes   int64                    // 当前已使用的内存
-	ll       *list.List               // 标准库双向链表
-	cache    map[string]*list.Element // k：字符串，v：双向链表节点指针
-	// optional and executed when an entry is purged.
+	maxBytes     int64                          // 允许使用的最大内存
+	maxEntries   int                            // 允许的最大条目数，0 表示不限制
+	defaultTTL   time.Duration                  // Add 使用的默认过期时间，0 表示永不过期
+	maxAge       time.Duration                  // 条目自插入起的最长存活时间，0 表示不限制
+	minRetention time.Duration                  // 淘汰时尽量不选插入时间短于它的节点
+	countHits    bool                           // 是否统计每个条目的命中次数
+	trackAccess  bool                           // 是否记录每个条目的插入和最近访问时间
+	codec        Codec                          // 非 nil 时 ByteView 值压缩后存储
+	observer     Observer                       // 可选的操作观察者，nil 表示不启用
+	excludeKeys  bool                           // 为 true 时 key 不计入内存占用（TTLCache 内部使用）
+	ghostSize    int                            // 幽灵列表长度，0 不启用，负数使用默认值
+	ghost        *ghostList                     // 最近被淘汰的 key
+	tags         map[string]map[string]struct{} // tag 到 key 集合的索引
+	expiries     expiryHeap                     // 设置了过期时间的条目，按过期时间排成小顶堆
+	expireSeq    uint64                         // 最近一次分配的 entry.expireSeq
+	nbytes       int64                          // 当前已使用的内存
+	ll           *entryList                     // 侵入式双向链表
+	cache        map[string]*entry              // k：字符串，v：双向链表节点指针
+	// optional and executed when an entry is purged. By then the entry is
+	// fully unlinked and accounted for, so the callback may call back into
+	// the cache, e.g. to re-add the key; a plain Cache has no lock to
+	// deadlock on. The same holds for OnEvictedMeta.
 	OnEvicted func(key string, value Value) //某条记录被移除时的回调函数，可以为 nil。
+	// optional and executed when a new key is stored, after its bytes are
+	// counted and before any eviction it causes.
+	OnInsert func(key string, value Value)
+	// optional and executed when an existing key's value is replaced, after
+	// the byte count is updated and before any eviction it causes.
+	OnUpdate func(key string, old, new Value)
+	// optional; like OnEvicted but also receives the entry's metadata.
+	OnEvictedMeta func(key string, value Value, meta interface{})
+	// optional and executed before an entry is added; a non-nil error rejects it.
+	Validate func(key string, value Value) error
+	// optional; when it returns false the entry keeps its place and
+	// capacity eviction tries the next-oldest one instead. If no entry may
+	// be evicted the cache stays over budget until one can be.
+	CanEvict func(key string, value Value) bool
+
+	admission *tinyLFU           // 可选的 TinyLFU 准入过滤器，nil 表示不启用
+	events    chan EvictionEvent // 淘汰事件流，第一次调用 Events 时创建
+	// onRemove 在条目移除后、OnEvicted 之前调用，Tiered 用它区分淘汰原因；
+	// 返回 true 表示条目转移到了别处，不再回调和通知
+	onRemove func(kv *entry, reason EvictionReason) bool
 }
 
 //双向链表节点的数据类型，
 //在链表中仍保存每个值对应的 key 的好处在于，淘汰队首节点时，需要用 key 从字典中删除对应的映射。
+// size 记录插入时计算的大小，淘汰时直接减去它，避免 value 变化后重新计算导致 nbytes 漂移。
 type entry struct {
-	key   string
-	value Value
+	key     string
+	value   Value
+	size    int64
+	expire  time.Time // 过期时间，零值表示永不过期
+	created time.Time // 插入时间，仅在设置了 maxAge、minRetention 或 trackAccess 时记录
+	hits    int64     // 命中次数，仅在开启 countHits 时统计
+	// accessed 是最近一次写入或 Get 的时间，仅在开启 trackAccess 时记录
+	accessed time.Time
+	pinned   bool // 被固定的节点不会因容量不足被淘汰
+	// reprieve 表示该 key 刚被淘汰过又回来了，下一次淘汰时跳过一次
+	reprieve bool
+	meta     interface{} // 附加在条目上的元数据，由 AddWithMeta 设置
+	tags     []string    // 由 AddWithTags 设置，同时登记在 Cache.tags 中
+	// compressed 表示 value 是 Add 时由 codec 压缩得到的 CompressedView
+	compressed bool
+	prev, next *entry // entryList 中的前后节点
+	heapIndex  int    // 在 Cache.expiries 中的下标，-1 表示不在堆中
+	expireSeq  uint64 // 设置过期时间的序号，过期时间相同时先设置的先移除
 }
 
 // Value use Len to count how many bytes it takes
@@ -27,60 +90,400 @@ type Value interface {
 
 // New is the Constructor of Cache
 func New(maxBytes int64, onEvicted func(string, Value)) *Cache {
-	return &Cache{
-		maxBytes:  maxBytes,
-		ll:        list.New(),
-		cache:     make(map[string]*list.Element),
-		OnEvicted: onEvicted,
-	}
+	return NewWithOptions(WithMaxBytes(maxBytes), WithOnEvicted(onEvicted))
+}
+
+// NewWithCapacity is like New but preallocates room for capacity entries,
+// avoiding repeated map growth while a large cache warms up.
+func NewWithCapacity(maxBytes int64, capacity int, onEvicted func(string, Value)) *Cache {
+	return NewWithOptions(WithMaxBytes(maxBytes), WithInitialCapacity(capacity), WithOnEvicted(onEvicted))
 }
 
-// Add adds a value to the cache.
+// NewTinyLFU is like New but guards eviction with a TinyLFU admission filter:
+// a new key is only stored if it has been seen more often than each of the
+// least recently used entries it would evict. counters sizes the frequency
+// sketch and should be about the expected number of entries.
+func NewTinyLFU(maxBytes int64, counters int, onEvicted func(string, Value)) *Cache {
+	return NewWithOptions(WithMaxBytes(maxBytes), WithTinyLFU(counters), WithOnEvicted(onEvicted))
+}
+
+// Add adds a value to the cache. It expires after the default TTL, if any.
+// Nil values, entries rejected by Validate or the admission filter and
+// entries larger than maxBytes are silently dropped; use AddChecked to see
+// why.
 func (c *Cache) Add(key string, value Value) {
-	if ele, ok := c.cache[key]; ok {
+	c.AddChecked(key, value)
+}
+
+// AddChecked is like Add but returns ErrNilValue, the error from Validate,
+// ErrValueTooLarge or ErrRejected, in which case the entry is not stored. A
+// value too large to cache also removes the key's previous value, since it
+// is stale. A nil error means the entry was stored, though like any other
+// it may have been evicted again by the time AddChecked returns.
+func (c *Cache) AddChecked(key string, value Value) error {
+	_, err := c.addChecked(key, value, c.expiry(0))
+	return err
+}
+
+func (c *Cache) addChecked(key string, value Value, expire time.Time) (*entry, error) {
+	if err := c.check(key, value); err != nil {
+		return nil, err
+	}
+	return c.add(key, value, expire)
+}
+
+// check returns ErrNilValue or the error from Validate for a value about to
+// be added, before anything in the cache is changed.
+func (c *Cache) check(key string, value Value) error {
+	if value == nil {
+		return ErrNilValue
+	}
+	if c.Validate != nil {
+		return c.Validate(key, value)
+	}
+	return nil
+}
+
+// add stores the entry and returns it, or nil if it was not kept. The map
+// is hashed once for an update and twice for an insert (lookup, then
+// assignment); callers use the returned entry instead of looking it up again.
+func (c *Cache) add(key string, value Value, expire time.Time) (*entry, error) {
+	if c.admission != nil {
+		c.admission.record(key)
+	}
+	compressed := false
+	if c.codec != nil {
+		value, compressed = c.compress(value)
+	}
+	size := c.sizeOf(key, value)
+	// 单个条目就超过上限时直接拒绝，否则会把整个缓存清空后再淘汰它自己
+	if c.maxBytes != 0 && size > c.maxBytes {
+		// 旧值已被新值取代，不能继续留在缓存里冒充最新值
+		if kv, ok := c.cache[key]; ok {
+			c.removeElement(kv, ReasonRemoved)
+		}
+		return nil, ErrValueTooLarge
+	}
+	kv, ok := c.cache[key]
+	if ok {
 		// 如果键存在，则更新对应节点的值，并将该节点移到队尾。
-		c.ll.MoveToFront(ele)
-		kv := ele.Value.(*entry)
+		c.ll.MoveToFront(kv)
 		// 更新长度
-		c.nbytes += int64(value.Len()) - int64(kv.value.Len())
+		c.nbytes += size - kv.size
+		old := kv.value
 		kv.value = value
+		kv.size = size
+		c.setExpire(kv, expire)
+		kv.compressed = compressed
+		kv.meta = nil
+		if kv.tags != nil {
+			c.untag(kv)
+		}
+		if c.tracksAge() {
+			kv.created = now()
+		}
+		if c.trackAccess {
+			kv.accessed = kv.created
+		}
+		if c.OnUpdate != nil {
+			c.OnUpdate(key, old, value)
+		}
 	} else {
 		// 不存在则新增，首先队尾添加新节点, 并字典中添加 key 和节点的映射关系。
-		ele := c.ll.PushFront(&entry{key, value})
-		c.cache[key] = ele
-		c.nbytes += int64(len(key)) + int64(value.Len())
+		if c.admission != nil && c.wouldEvict(size) && !c.admits(key, size) {
+			// 新增会触发淘汰，冷 key 不允许挤掉热 key
+			return nil, ErrRejected
+		}
+		kv = &entry{key: key, value: value, size: size, compressed: compressed, heapIndex: -1}
+		c.setExpire(kv, expire)
+		if c.ghost != nil {
+			kv.reprieve = c.ghost.take(key)
+		}
+		if c.tracksAge() {
+			kv.created = now()
+		}
+		if c.trackAccess {
+			kv.accessed = kv.created
+		}
+		c.ll.PushFront(kv)
+		c.cache[key] = kv
+		c.nbytes += size
+		if c.OnInsert != nil {
+			c.OnInsert(key, value)
+		}
+	}
+	if c.maxAge != 0 {
+		c.removeStale()
 	}
 	//更新 c.nbytes，如果超过了设定的最大值 c.maxBytes，则移除最少访问的节点。
-	for c.maxBytes != 0 && c.maxBytes < c.nbytes {
-		c.RemoveOldest()
+	// 如果剩下的都是被固定的节点，允许暂时超出预算。
+	for c.overBudget() && c.removeOldest() {
 	}
+	// 淘汰不会移动其他节点，除非有节点被豁免移到了队尾，这时才需要再查一次字典
+	if c.ll.Front() != kv && c.cache[key] != kv {
+		return nil, nil
+	}
+	return kv, nil
+}
+
+// sizeOf returns the number of bytes an entry is accounted for.
+func (c *Cache) sizeOf(key string, value Value) int64 {
+	if c.excludeKeys {
+		return int64(value.Len())
+	}
+	return int64(len(key)) + int64(value.Len())
+}
+
+// overBudget reports whether the cache exceeds maxBytes or maxEntries.
+func (c *Cache) overBudget() bool {
+	return (c.maxBytes != 0 && c.maxBytes < c.nbytes) ||
+		(c.maxEntries != 0 && c.maxEntries < c.ll.Len())
+}
+
+// wouldEvict reports whether adding a new entry of size bytes would push the
+// cache over budget.
+func (c *Cache) wouldEvict(size int64) bool {
+	return (c.maxBytes != 0 && c.maxBytes < c.nbytes+size) ||
+		(c.maxEntries != 0 && c.maxEntries < c.ll.Len()+1)
 }
 
 // Get look ups a key's value
 //查找主要有 2 个步骤，第一步是从字典中找到对应的双向链表的节点，第二步，将该节点移动到队尾
 func (c *Cache) Get(key string) (value Value, ok bool) {
-	if ele, ok := c.cache[key]; ok {
-		//如果键对应的链表节点存在，则将对应节点移动到队尾，并返回查找到的值。在这里约定 front 为队尾
-		c.ll.MoveToFront(ele)
-		kv := ele.Value.(*entry)
-		return kv.value, true
+	return c.GetOpt(key, true)
+}
+
+// GetOpt looks up a key's value. With promote false the lookup does not
+// count as a use: the entry keeps its place in the LRU order and its hit
+// and frequency counts are unchanged, which suits background scans.
+// Expired entries are still removed.
+func (c *Cache) GetOpt(key string, promote bool) (value Value, ok bool) {
+	if kv := c.lookup(key, promote); kv != nil {
+		return c.valueOf(kv)
 	}
 	return
 }
 
+// lookup returns the live entry for key, marking it as recently used when
+// promote is set. Expired or stale entries are removed and reported as a
+// miss.
+func (c *Cache) lookup(key string, promote bool) *entry {
+	if promote && c.admission != nil {
+		c.admission.record(key)
+	}
+	if kv, ok := c.cache[key]; ok {
+		if reason, dead := c.dead(kv); dead {
+			c.removeElement(kv, reason)
+			if c.observer != nil {
+				c.observer.OnMiss(key)
+			}
+			return nil
+		}
+		//如果键对应的链表节点存在，则将对应节点移动到队尾，并返回查找到的值。在这里约定 front 为队尾
+		if promote {
+			c.ll.MoveToFront(kv)
+			if c.countHits {
+				kv.hits++
+			}
+			if c.trackAccess {
+				kv.accessed = now()
+			}
+		}
+		if c.observer != nil {
+			c.observer.OnHit(key)
+		}
+		return kv
+	}
+	if c.observer != nil {
+		c.observer.OnMiss(key)
+	}
+	return nil
+}
+
 // RemoveOldest removes the oldest item
 // 缓存淘汰,移除最近最少访问的节点（队首）
+// Pinned entries and entries vetoed by CanEvict are skipped.
 func (c *Cache) RemoveOldest() {
-	ele := c.ll.Back() // c.ll.Back() 取到队首节点，从链表中删除。
+	c.removeOldest()
+}
 
-	if ele != nil {
-		c.ll.Remove(ele)
-		kv := ele.Value.(*entry)
-		delete(c.cache, kv.key) // 从字典中 c.cache 删除该节点的映射关系。
-		c.nbytes -= int64(len(kv.key)) + int64(kv.value.Len())
-		if c.OnEvicted != nil {
-			c.OnEvicted(kv.key, kv.value)
+// removeOldest evicts the victim, if any, and reports whether it did.
+func (c *Cache) removeOldest() bool {
+	for {
+		kv := c.victim()
+		if kv == nil {
+			return false
+		}
+		if kv.reprieve {
+			// 曾被过早淘汰的 key，这次放过它，移到队尾
+			kv.reprieve = false
+			c.ll.MoveToFront(kv)
+			continue
 		}
+		// 先记入幽灵列表再回调，回调里重新加入的 key 不会被误记
+		if c.ghost != nil {
+			c.ghost.add(kv.key)
+		}
+		c.removeElement(kv, ReasonCapacity)
+		return true
+	}
+}
+
+// admits reports whether the TinyLFU filter lets key in when storing size
+// more bytes would evict entries. key must be seen more often than every
+// entry that would have to make room for it, taken from the cold end of the
+// list. Pinned entries are skipped but CanEvict, minRetention and reprieves
+// are not consulted, so the set compared against estimates, rather than
+// predicts exactly, what the insert will evict.
+func (c *Cache) admits(key string, size int64) bool {
+	var needBytes int64
+	if c.maxBytes != 0 {
+		needBytes = c.nbytes + size - c.maxBytes
+	}
+	needEntries := 0
+	if c.maxEntries != 0 {
+		needEntries = c.ll.Len() + 1 - c.maxEntries
+	}
+	for kv := c.ll.Back(); kv != nil && (needBytes > 0 || needEntries > 0); kv = c.ll.Prev(kv) {
+		if kv.pinned {
+			continue
+		}
+		if !c.admission.admit(key, kv.key) {
+			return false
+		}
+		needBytes -= kv.size
+		needEntries--
+	}
+	return true
+}
+
+// victimScan bounds how many of the least recently used entries victim looks
+// at for one old enough under minRetention, so an eviction stays O(1).
+const victimScan = 32
+
+// victim returns the least recently used entry that may be evicted, that is
+// one that is not pinned and not vetoed by CanEvict. Entries it passes over
+// keep their place in the list. With minRetention set, entries younger than
+// it are passed over too; if none of the victimScan least recently used
+// entries is old enough, the least recently used evictable one among them is
+// returned. Only when that window holds no evictable entry at all does the
+// scan go further, up to the first one it finds. Ties are always broken by
+// list position, never by timestamps, which may be equal. It returns nil if
+// no entry may be evicted.
+func (c *Cache) victim() *entry {
+	var t time.Time
+	if c.minRetention != 0 {
+		t = now()
+	}
+	var oldest *entry
+	// c.ll.Back() 取到队首节点；不能淘汰的节点原地跳过，不改变访问顺序
+	scanned := 0
+	for kv := c.ll.Back(); kv != nil && (oldest == nil || scanned < victimScan); kv = c.ll.Prev(kv) {
+		scanned++
+		switch {
+		case kv.pinned || (c.CanEvict != nil && !c.CanEvict(kv.key, kv.value)):
+		case c.minRetention == 0 || t.Sub(kv.created) >= c.minRetention:
+			return kv
+		case oldest == nil:
+			oldest = kv
+		}
+	}
+	// 扫描范围内都在保留期内，退回淘汰其中最久未访问的节点
+	return oldest
+}
+
+// SetMaxBytes changes the byte limit, evicting least recently used entries
+// right away when it is lowered. 0 removes the limit.
+func (c *Cache) SetMaxBytes(maxBytes int64) {
+	c.maxBytes = maxBytes
+	for c.overBudget() && c.removeOldest() {
+	}
+}
+
+// GetMaxBytes returns the byte limit; 0 means unlimited.
+func (c *Cache) GetMaxBytes() int64 {
+	return c.maxBytes
+}
+
+// Trim evicts least recently used entries until at most targetBytes are in
+// use and returns how many were evicted. Unlike lowering maxBytes it is a
+// one-off: the cache may grow back to maxBytes afterwards. Pinned entries
+// are kept, so the target may not be reached.
+func (c *Cache) Trim(targetBytes int64) int {
+	n := 0
+	for c.nbytes > targetBytes && c.removeOldest() {
+		n++
+	}
+	return n
+}
+
+// Remove removes key from the cache and reports whether it was present.
+// OnEvicted, if set, is called for the removed entry.
+func (c *Cache) Remove(key string) bool {
+	if kv, ok := c.cache[key]; ok {
+		c.removeElement(kv, ReasonRemoved)
+		return true
+	}
+	return false
+}
+
+// Clear removes every entry, calling OnEvicted for each one.
+func (c *Cache) Clear() {
+	for kv := c.ll.Back(); kv != nil; kv = c.ll.Back() {
+		c.removeElement(kv, ReasonRemoved)
+	}
+}
+
+// Compact rebuilds the internal map sized to the current number of entries.
+// Go maps never shrink, so after a spike of entries subsides the map keeps
+// its peak bucket count; Compact releases that memory. Entries and their LRU
+// order are unchanged. It is O(n) and meant to be called in quiet periods.
+func (c *Cache) Compact() {
+	m := make(map[string]*entry, len(c.cache))
+	for k, kv := range c.cache {
+		m[k] = kv
+	}
+	c.cache = m
+	// 过期堆的底层数组同样只增不减
+	if cap(c.expiries) > 2*len(c.expiries) {
+		c.expiries = append(expiryHeap(nil), c.expiries...)
+	}
+}
+
+// removeElement unlinks kv and then runs the eviction callbacks. Callers
+// that remove several entries must expect the callbacks to have removed or
+// replaced entries they have not reached yet.
+func (c *Cache) removeElement(kv *entry, reason EvictionReason) {
+	c.unlink(kv)
+	if c.onRemove != nil && c.onRemove(kv, reason) {
+		return
+	}
+	if c.OnEvicted != nil {
+		c.OnEvicted(kv.key, kv.value)
+	}
+	if c.OnEvictedMeta != nil {
+		c.OnEvictedMeta(kv.key, kv.value, kv.meta)
+	}
+	if c.observer != nil {
+		c.observer.OnEvict(kv.key, reason)
+	}
+	if c.events != nil {
+		c.emit(EvictionEvent{Key: kv.key, Value: kv.value, Meta: kv.meta, Reason: reason})
+	}
+}
+
+// unlink takes kv out of the list, the map, the expiry heap and the tag
+// index without running any callbacks, for entries that move elsewhere
+// rather than leave.
+func (c *Cache) unlink(kv *entry) {
+	c.ll.Remove(kv)
+	delete(c.cache, kv.key) // 从字典中 c.cache 删除该节点的映射关系。
+	c.nbytes -= kv.size
+	if kv.heapIndex >= 0 {
+		heap.Remove(&c.expiries, kv.heapIndex)
+	}
+	if kv.tags != nil {
+		c.untag(kv)
 	}
 }
 
@@ -88,3 +491,8 @@ func (c *Cache) RemoveOldest() {
 func (c *Cache) Len() int {
 	return c.ll.Len()
 }
+
+// Bytes returns the number of bytes currently accounted to the cache.
+func (c *Cache) Bytes() int64 {
+	return c.nbytes
+}
@@ -1,8 +1,11 @@
 package lru
 
 import (
+	"errors"
 	"reflect"
+	"strconv"
 	"testing"
+	"time"
 )
 
 type String string
@@ -63,3 +66,209 @@ func TestAdd(t *testing.T) {
 		t.Fatal("expected 6 but got", lru.nbytes)
 	}
 }
+
+type mutable struct {
+	b []byte
+}
+
+func (m *mutable) Len() int {
+	return len(m.b)
+}
+
+func TestBytesAfterRemove(t *testing.T) {
+	lru := New(int64(0), nil)
+	v := &mutable{b: []byte("12")}
+	lru.Add("key1", v)
+	lru.Add("key2", String("123456"))
+	// value 在缓存外部被修改，淘汰时不应重新计算大小
+	v.b = append(v.b, "3456789"...)
+	lru.Add("key2", String("1"))
+	for lru.Len() > 0 {
+		lru.RemoveOldest()
+	}
+	if lru.Bytes() != 0 {
+		t.Fatal("expected 0 bytes after removing all entries but got", lru.Bytes())
+	}
+}
+
+func TestAddChecked(t *testing.T) {
+	errEmpty := errors.New("empty key")
+	lru := New(int64(0), nil)
+	lru.Validate = func(key string, value Value) error {
+		if key == "" {
+			return errEmpty
+		}
+		return nil
+	}
+	if err := lru.AddChecked("", String("v")); err != errEmpty {
+		t.Fatalf("expected %v but got %v", errEmpty, err)
+	}
+	lru.Add("", String("v"))
+	if lru.Len() != 0 || lru.Bytes() != 0 {
+		t.Fatalf("rejected entry should not be stored")
+	}
+	if err := lru.AddChecked("key1", String("v")); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := lru.Get("key1"); !ok {
+		t.Fatalf("cache hit key1 failed")
+	}
+}
+
+func TestNewWithCapacity(t *testing.T) {
+	lru := NewWithCapacity(int64(0), 1024, nil)
+	lru.Add("key1", String("1234"))
+	if v, ok := lru.Get("key1"); !ok || string(v.(String)) != "1234" {
+		t.Fatalf("cache hit key1=1234 failed")
+	}
+}
+
+func TestTrim(t *testing.T) {
+	lru := New(int64(100), nil)
+	lru.Add("k1", String("v1"))
+	lru.Add("k2", String("v2"))
+	lru.Add("k3", String("v3"))
+	if n := lru.Trim(int64(len("k3v3"))); n != 2 {
+		t.Fatalf("expected 2 evictions but got %d", n)
+	}
+	if _, ok := lru.Get("k3"); !ok || lru.Len() != 1 {
+		t.Fatalf("Trim should keep the most recently used entry")
+	}
+	// 上限不变，之后可以继续写满
+	lru.Add("k4", String("v4"))
+	lru.Add("k5", String("v5"))
+	if lru.Len() != 3 {
+		t.Fatalf("Trim should not lower maxBytes")
+	}
+}
+
+func TestGetOpt(t *testing.T) {
+	lru := NewWithOptions(WithMaxEntries(2), WithHitCounts())
+	lru.Add("k1", String("v1"))
+	lru.Add("k2", String("v2"))
+	if v, ok := lru.GetOpt("k1", false); !ok || string(v.(String)) != "v1" {
+		t.Fatalf("GetOpt without promote should still return k1")
+	}
+	if len(lru.HotKeys(1)) != 0 {
+		t.Fatalf("GetOpt without promote should not count a hit")
+	}
+	lru.Add("k3", String("v3"))
+	if _, ok := lru.Get("k1"); ok {
+		t.Fatalf("k1 should stay the oldest after a non-promoting read")
+	}
+}
+
+func TestSetMaxBytes(t *testing.T) {
+	lru := New(int64(0), nil)
+	lru.Add("k1", String("v1"))
+	lru.Add("k2", String("v2"))
+	lru.SetMaxBytes(int64(len("k2v2")))
+	if _, ok := lru.Get("k1"); ok || lru.Len() != 1 || lru.GetMaxBytes() != int64(len("k2v2")) {
+		t.Fatalf("lowering maxBytes should evict immediately")
+	}
+}
+
+func TestCanEvict(t *testing.T) {
+	inUse := map[string]bool{"k1": true}
+	lru := NewWithOptions(WithMaxEntries(2), WithCanEvict(func(key string, value Value) bool {
+		return !inUse[key]
+	}))
+	lru.Add("k1", String("1"))
+	lru.Add("k2", String("2"))
+	lru.Add("k3", String("3"))
+	if _, ok := lru.Get("k1"); !ok {
+		t.Fatalf("k1 is in use and should not be evicted")
+	}
+	if _, ok := lru.Get("k2"); ok {
+		t.Fatalf("k2 should be evicted in place of k1")
+	}
+	// 没有可淘汰的节点时允许暂时超出容量
+	inUse["k3"], inUse["k4"] = true, true
+	lru.Add("k4", String("4"))
+	if lru.Len() != 3 {
+		t.Fatalf("expected the cache to exceed its budget, got %d entries", lru.Len())
+	}
+}
+
+func TestOnInsertOnUpdate(t *testing.T) {
+	var log []string
+	lru := NewWithOptions(WithMaxEntries(1),
+		WithOnInsert(func(key string, value Value) {
+			log = append(log, "insert "+key+"="+string(value.(String)))
+		}),
+		WithOnUpdate(func(key string, old, new Value) {
+			log = append(log, "update "+key+" "+string(old.(String))+"->"+string(new.(String)))
+		}),
+		WithOnEvicted(func(key string, value Value) {
+			log = append(log, "evict "+key)
+		}))
+	lru.Add("k1", String("1"))
+	lru.Add("k1", String("2"))
+	lru.Add("k2", String("3"))
+	want := []string{"insert k1=1", "update k1 1->2", "insert k2=3", "evict k1"}
+	if !reflect.DeepEqual(log, want) {
+		t.Fatalf("got %v, want %v", log, want)
+	}
+}
+
+func TestCompact(t *testing.T) {
+	lru := New(int64(0), nil)
+	for i := 0; i < 1000; i++ {
+		lru.AddWithTTL(strconv.Itoa(i), String("v"), time.Hour)
+	}
+	for i := 3; i < 1000; i++ {
+		lru.Remove(strconv.Itoa(i))
+	}
+	lru.Get("0")
+	lru.Compact()
+	if lru.Len() != 3 || lru.Bytes() != 6 || len(lru.expiries) != 3 {
+		t.Fatalf("Compact should keep every entry, got %d", lru.Len())
+	}
+	// 访问顺序不变，淘汰顺序为 1, 2, 0
+	var keys []string
+	lru.OnEvicted = func(key string, value Value) { keys = append(keys, key) }
+	for lru.Len() > 0 {
+		lru.RemoveOldest()
+	}
+	if expect := []string{"1", "2", "0"}; !reflect.DeepEqual(expect, keys) {
+		t.Fatalf("expect eviction order %s but got %s", expect, keys)
+	}
+}
+
+func TestReentrantOnEvicted(t *testing.T) {
+	var lru *Cache
+	// 被淘汰的条目降级后重新放回同一个缓存，降级过的条目直接丢弃
+	lru = NewWithOptions(WithMaxEntries(2), WithOnEvicted(func(key string, value Value) {
+		if value.(String) != "demoted" {
+			lru.Add(key, String("demoted"))
+		}
+	}))
+	lru.Add("k1", String("1"))
+	lru.Add("k2", String("2"))
+	lru.Add("k3", String("3"))
+	if err := lru.checkInvariants(); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := lru.Get("k3"); !ok || v.(String) != "demoted" || lru.Len() != 2 {
+		t.Fatalf("k3 should have been demoted in place, got %v", v)
+	}
+
+	// 回调删除了批量操作尚未处理到的条目
+	lru = NewWithOptions(WithOnEvicted(func(key string, value Value) {
+		lru.Remove("k1")
+		lru.Remove("k2")
+	}))
+	lru.AddWithTags("k1", String("1"), "t")
+	lru.AddWithTags("k2", String("2"), "t")
+	if n := lru.InvalidateTag("t"); n != 1 || lru.Len() != 0 {
+		t.Fatalf("expected one entry removed by InvalidateTag itself, got %d", n)
+	}
+	lru.Add("k1", String("1"))
+	lru.Add("k2", String("2"))
+	if n := lru.RemovePrefix("k"); n != 1 || lru.Len() != 0 {
+		t.Fatalf("expected one entry removed by RemovePrefix itself, got %d", n)
+	}
+	if err := lru.checkInvariants(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,22 @@
+package lru
+
+// AddWithMeta adds a value together with out-of-band metadata, such as a
+// source or version, that can be read back with GetMeta. The metadata stays
+// with the entry until it is replaced: a later plain Add of the same key
+// clears it. OnEvictedMeta and Events receive it when the entry leaves.
+func (c *Cache) AddWithMeta(key string, value Value, meta interface{}) error {
+	kv, err := c.addChecked(key, value, c.expiry(0))
+	if kv != nil {
+		kv.meta = meta
+	}
+	return err
+}
+
+// GetMeta returns the metadata stored for key. It does not count as a use
+// of the entry.
+func (c *Cache) GetMeta(key string) (interface{}, bool) {
+	if kv := c.lookup(key, false); kv != nil {
+		return kv.meta, true
+	}
+	return nil, false
+}
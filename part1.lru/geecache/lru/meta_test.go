@@ -0,0 +1,29 @@
+package lru
+
+import "testing"
+
+func TestMeta(t *testing.T) {
+	var evictedMeta interface{}
+	lru := New(int64(len("k1v1")), nil)
+	lru.OnEvictedMeta = func(key string, value Value, meta interface{}) {
+		evictedMeta = meta
+	}
+	if err := lru.AddWithMeta("k1", String("v1"), 7); err != nil {
+		t.Fatal(err)
+	}
+	if meta, ok := lru.GetMeta("k1"); !ok || meta != 7 {
+		t.Fatalf("expected meta 7 but got %v", meta)
+	}
+	if _, ok := lru.GetMeta("missing"); ok {
+		t.Fatalf("GetMeta should miss for an unknown key")
+	}
+	lru.Add("k2", String("v2"))
+	if evictedMeta != 7 {
+		t.Fatalf("OnEvictedMeta should receive the metadata, got %v", evictedMeta)
+	}
+	lru.AddWithMeta("k2", String("v2"), "src")
+	lru.Add("k2", String("v2"))
+	if meta, _ := lru.GetMeta("k2"); meta != nil {
+		t.Fatalf("a plain Add should clear the metadata, got %v", meta)
+	}
+}
@@ -0,0 +1,89 @@
+package lru
+
+import "time"
+
+// GetMultiLoad returns the values for keys. Cached keys are served directly;
+// the rest are passed to loader in a single call, and whatever it returns is
+// added to the cache and merged into the result. Keys the loader does not
+// return are treated as misses and left out of the result, and so are nil
+// values and values rejected by Validate. Loaded values too large to cache
+// or kept out by the admission filter are returned without being cached. If
+// the loader fails, the hits found so far are returned together with a
+// *LoaderError.
+func (c *Cache) GetMultiLoad(keys []string, loader func(missing []string) (map[string]Value, error)) (map[string]Value, error) {
+	found := make(map[string]Value, len(keys))
+	var missing []string
+	for _, key := range keys {
+		if _, ok := found[key]; ok {
+			continue
+		}
+		if v, ok := c.Get(key); ok {
+			found[key] = v
+		} else {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return found, nil
+	}
+	start := time.Now()
+	loaded, err := loader(missing)
+	if c.observer != nil {
+		d := time.Since(start)
+		for _, key := range missing {
+			c.observer.OnLoad(key, d, err)
+		}
+	}
+	if err != nil {
+		return found, loaderError(err, missing...)
+	}
+	for _, key := range missing {
+		// 只接受请求过的 key，loader 多返回的忽略
+		if v, ok := loaded[key]; ok {
+			// 被拒绝的值不能当作命中返回
+			if err := c.AddChecked(key, v); err == nil || err == ErrValueTooLarge || err == ErrRejected {
+				found[key] = v
+			}
+		}
+	}
+	return found, nil
+}
+
+// GetOrLoadRelated returns the value for key, calling loader on a miss.
+// The loader may return several related entries at once (say, a whole row
+// family); all of them are added so later lookups hit, with key added last
+// so it is the most recently used. If the result lacks key, ErrNotFound is
+// returned after caching the other entries. A loader failure is returned as
+// a *LoaderError, and a value for key too large to cache is returned along
+// with ErrValueTooLarge. A nil value for key yields ErrNilValue and one
+// rejected by Validate yields its error, with a nil Value.
+func (c *Cache) GetOrLoadRelated(key string, loader func(key string) (map[string]Value, error)) (Value, error) {
+	if v, ok := c.Get(key); ok {
+		return v, nil
+	}
+	start := time.Now()
+	loaded, err := loader(key)
+	if c.observer != nil {
+		c.observer.OnLoad(key, time.Since(start), err)
+	}
+	if err != nil {
+		return nil, loaderError(err, key)
+	}
+	for k, v := range loaded {
+		if k != key {
+			c.Add(k, v)
+		}
+	}
+	v, ok := loaded[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	switch err := c.AddChecked(key, v); err {
+	case nil, ErrRejected:
+		return v, nil
+	case ErrValueTooLarge:
+		return v, err
+	default:
+		return nil, err
+	}
+}
@@ -0,0 +1,94 @@
+package lru
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestGetMultiLoad(t *testing.T) {
+	lru := New(int64(0), nil)
+	lru.Add("k1", String("v1"))
+
+	var asked []string
+	loader := func(missing []string) (map[string]Value, error) {
+		asked = missing
+		return map[string]Value{"k2": String("v2"), "other": String("x")}, nil
+	}
+	got, err := lru.GetMultiLoad([]string{"k1", "k2", "k3", "k1"}, loader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := []string{"k2", "k3"}; !reflect.DeepEqual(expect, asked) {
+		t.Fatalf("loader should be called with %s but got %s", expect, asked)
+	}
+	expect := map[string]Value{"k1": String("v1"), "k2": String("v2")}
+	if !reflect.DeepEqual(expect, got) {
+		t.Fatalf("expected %v but got %v", expect, got)
+	}
+	if _, ok := lru.Get("k2"); !ok {
+		t.Fatalf("loaded k2 should be cached")
+	}
+	if _, ok := lru.Get("other"); ok {
+		t.Fatalf("keys that were not requested should not be cached")
+	}
+}
+
+func TestGetMultiLoadError(t *testing.T) {
+	lru := New(int64(0), nil)
+	lru.Add("k1", String("v1"))
+	errLoad := errors.New("backend down")
+	got, err := lru.GetMultiLoad([]string{"k1", "k2"}, func([]string) (map[string]Value, error) {
+		return nil, errLoad
+	})
+	if !errors.Is(err, errLoad) || len(got) != 1 {
+		t.Fatalf("expected hits and the loader error, got %v, %v", got, err)
+	}
+}
+
+func TestGetOrLoadRelated(t *testing.T) {
+	lru := New(int64(len("row:1:av1row:1:bv2")), nil)
+	loads := 0
+	loader := func(key string) (map[string]Value, error) {
+		loads++
+		return map[string]Value{"row:1:a": String("v1"), "row:1:b": String("v2")}, nil
+	}
+	v, err := lru.GetOrLoadRelated("row:1:a", loader)
+	if err != nil || string(v.(String)) != "v1" {
+		t.Fatalf("unexpected %v, %v", v, err)
+	}
+	if v, err = lru.GetOrLoadRelated("row:1:b", loader); err != nil || string(v.(String)) != "v2" || loads != 1 {
+		t.Fatalf("related key should already be cached, got %v after %d loads", v, loads)
+	}
+	if _, err = lru.GetOrLoadRelated("row:2:a", loader); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound but got %v", err)
+	}
+}
+
+func TestLoadRejected(t *testing.T) {
+	errBad := errors.New("bad value")
+	lru := NewWithOptions(WithValidate(func(key string, value Value) error {
+		if value.(String) == "bad" {
+			return errBad
+		}
+		return nil
+	}))
+	got, err := lru.GetMultiLoad([]string{"k1", "k2", "k3"}, func([]string) (map[string]Value, error) {
+		return map[string]Value{"k1": String("bad"), "k2": nil, "k3": String("v3")}, nil
+	})
+	// 被拒绝的值既不缓存也不返回
+	if expect := map[string]Value{"k3": String("v3")}; err != nil || !reflect.DeepEqual(expect, got) || lru.Len() != 1 {
+		t.Fatalf("rejected values should be left out, got %v, %v", got, err)
+	}
+	v, err := lru.GetOrLoadRelated("k4", func(string) (map[string]Value, error) {
+		return map[string]Value{"k4": String("bad")}, nil
+	})
+	if err != errBad || v != nil {
+		t.Fatalf("expected the Validate error, got %v, %v", v, err)
+	}
+	if _, err = lru.GetOrLoadRelated("k5", func(string) (map[string]Value, error) {
+		return map[string]Value{"k5": nil}, nil
+	}); err != ErrNilValue {
+		t.Fatalf("expected ErrNilValue but got %v", err)
+	}
+}
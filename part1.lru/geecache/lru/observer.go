@@ -0,0 +1,19 @@
+package lru
+
+import "time"
+
+// Observer receives a callback for each cache operation, for logging or
+// tracing. Its methods run synchronously on the calling goroutine (with the
+// lock held for SafeCache) and should return quickly.
+type Observer interface {
+	// OnHit is called when Get finds a live entry.
+	OnHit(key string)
+	// OnMiss is called when Get finds nothing, or only an expired entry.
+	OnMiss(key string)
+	// OnEvict is called whenever an entry leaves the cache.
+	OnEvict(key string, reason EvictionReason)
+	// OnLoad is called after a loader call made by GetMultiLoad or
+	// LoadingCache. For a batch load it is called once per requested key
+	// with the duration and error of the whole batch.
+	OnLoad(key string, d time.Duration, err error)
+}
@@ -0,0 +1,35 @@
+package lru
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type recorder struct {
+	ops []string
+}
+
+func (r *recorder) OnHit(key string)  { r.ops = append(r.ops, "hit "+key) }
+func (r *recorder) OnMiss(key string) { r.ops = append(r.ops, "miss "+key) }
+func (r *recorder) OnEvict(key string, reason EvictionReason) {
+	r.ops = append(r.ops, "evict "+key+" "+reason.String())
+}
+func (r *recorder) OnLoad(key string, d time.Duration, err error) {
+	r.ops = append(r.ops, "load "+key)
+}
+
+func TestObserver(t *testing.T) {
+	r := &recorder{}
+	lru := NewWithOptions(WithMaxEntries(1), WithObserver(r))
+	lru.Add("k1", String("v1"))
+	lru.Get("k1")
+	lru.Get("k2")
+	lru.GetMultiLoad([]string{"k2"}, func(missing []string) (map[string]Value, error) {
+		return map[string]Value{"k2": String("v2")}, nil
+	})
+	expect := []string{"hit k1", "miss k2", "miss k2", "load k2", "evict k1 capacity"}
+	if !reflect.DeepEqual(expect, r.ops) {
+		t.Fatalf("expected %q but got %q", expect, r.ops)
+	}
+}
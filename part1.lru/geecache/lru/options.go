@@ -0,0 +1,224 @@
+package lru
+
+import "time"
+
+// Option configures a Cache created by NewWithOptions.
+type Option func(*Cache)
+
+// SafeOption configures a SafeCache created by NewSafe. Every Option is a
+// SafeOption applied to the underlying Cache; WithLockStats and WithAsyncAdd
+// only make sense for a SafeCache and are SafeOptions alone, so passing them
+// to NewWithOptions does not compile.
+type SafeOption interface {
+	applySafe(s *SafeCache)
+}
+
+func (o Option) applySafe(s *SafeCache) {
+	o(s.lru)
+}
+
+// safeOption is a SafeOption that sets SafeCache fields.
+type safeOption func(*SafeCache)
+
+func (o safeOption) applySafe(s *SafeCache) {
+	o(s)
+}
+
+// NewWithOptions creates a Cache configured by opts. Without options the
+// cache is unbounded, has no eviction callback and no admission filter.
+func NewWithOptions(opts ...Option) *Cache {
+	c := &Cache{ll: newEntryList()}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.cache == nil {
+		c.cache = make(map[string]*entry)
+	}
+	if c.ghostSize < 0 {
+		// 默认取条目上限的四分之一
+		c.ghostSize = defaultGhostEntries
+		if c.maxEntries > 0 {
+			c.ghostSize = (c.maxEntries + 3) / 4
+		}
+	}
+	if c.ghostSize > 0 {
+		c.ghost = newGhostList(c.ghostSize)
+	}
+	return c
+}
+
+// WithMaxBytes limits the total size of keys and values. Default 0, no limit.
+func WithMaxBytes(maxBytes int64) Option {
+	return func(c *Cache) {
+		c.maxBytes = maxBytes
+	}
+}
+
+// WithMaxEntries limits the number of entries. Default 0, no limit.
+// It can be combined with WithMaxBytes; whichever is hit first evicts.
+func WithMaxEntries(maxEntries int) Option {
+	return func(c *Cache) {
+		c.maxEntries = maxEntries
+	}
+}
+
+// WithDefaultTTL sets the TTL applied by Add and by AddWithTTL with a zero
+// ttl. Default 0, entries never expire.
+func WithDefaultTTL(ttl time.Duration) Option {
+	return func(c *Cache) {
+		c.defaultTTL = ttl
+	}
+}
+
+// WithMaxAge bounds how long an entry may stay cached after it was inserted
+// or its value last replaced, regardless of how recently it was read. Stale
+// entries are removed lazily by Get and from the cold end of the list on Add.
+// Default 0, no limit.
+func WithMaxAge(maxAge time.Duration) Option {
+	return func(c *Cache) {
+		c.maxAge = maxAge
+	}
+}
+
+// WithMinRetention makes eviction pass over entries inserted less than d ago
+// in favour of older ones, so a freshly loaded value is not thrown away by
+// the next large Add. Eviction only looks at the 32 least recently used
+// entries, so each Add stays O(1): if none of those is old enough, the
+// least recently used of them that is not pinned or vetoed by CanEvict is
+// evicted anyway, even if an older entry sits further up the list.
+// Default 0, strict LRU.
+func WithMinRetention(d time.Duration) Option {
+	return func(c *Cache) {
+		c.minRetention = d
+	}
+}
+
+// WithOnEvicted sets the callback executed when an entry is purged.
+// Default nil.
+func WithOnEvicted(onEvicted func(key string, value Value)) Option {
+	return func(c *Cache) {
+		c.OnEvicted = onEvicted
+	}
+}
+
+// WithOnInsert sets the callback run when a new key is stored. Default nil.
+func WithOnInsert(onInsert func(key string, value Value)) Option {
+	return func(c *Cache) {
+		c.OnInsert = onInsert
+	}
+}
+
+// WithOnUpdate sets the callback run when a key's value is replaced.
+// Default nil.
+func WithOnUpdate(onUpdate func(key string, old, new Value)) Option {
+	return func(c *Cache) {
+		c.OnUpdate = onUpdate
+	}
+}
+
+// WithValidate sets the hook run before each add. Default nil, accept all.
+func WithValidate(validate func(key string, value Value) error) Option {
+	return func(c *Cache) {
+		c.Validate = validate
+	}
+}
+
+// WithCanEvict sets the hook that may veto capacity evictions. Default nil,
+// every unpinned entry may be evicted.
+func WithCanEvict(canEvict func(key string, value Value) bool) Option {
+	return func(c *Cache) {
+		c.CanEvict = canEvict
+	}
+}
+
+// WithInitialCapacity preallocates room for n entries. Default 0, the map
+// grows on demand.
+func WithInitialCapacity(n int) Option {
+	return func(c *Cache) {
+		c.cache = make(map[string]*entry, n)
+	}
+}
+
+// WithCodec makes the cache store ByteView values compressed with codec.
+// Len of the compressed form is what counts against maxBytes, and Get
+// decompresses back to a ByteView, trading CPU on every read for capacity.
+// OnEvicted and Events see the stored CompressedView. Other Value types are
+// stored unchanged. Default nil, no compression.
+func WithCodec(codec Codec) Option {
+	return func(c *Cache) {
+		c.codec = codec
+	}
+}
+
+// WithObserver reports hits, misses, evictions and loads to o.
+// Default nil, no reporting.
+func WithObserver(o Observer) Option {
+	return func(c *Cache) {
+		c.observer = o
+	}
+}
+
+// WithHitCounts enables per-entry hit counters reported by HotKeys.
+// Default off, so Get does no extra bookkeeping.
+func WithHitCounts() Option {
+	return func(c *Cache) {
+		c.countHits = true
+	}
+}
+
+// WithAccessTimes records when each entry was added and last read, as
+// reported by EntryInfo. Default off, so Get does not read the clock.
+func WithAccessTimes() Option {
+	return func(c *Cache) {
+		c.trackAccess = true
+	}
+}
+
+// WithLockStats makes a SafeCache time every lock acquisition and report
+// the waits through Stats. It costs two clock reads per operation.
+// Default off.
+func WithLockStats() SafeOption {
+	return safeOption(func(s *SafeCache) {
+		s.measure = true
+	})
+}
+
+// WithAsyncAdd makes a SafeCache queue Add and AddWithTTL in a buffer of n
+// entries that a background goroutine applies, so callers do not pay for
+// eviction themselves. Add only blocks while the buffer is full.
+//
+// Queued adds are not durable: they are lost if the process dies first.
+// A Get right after Add may miss, and a queued add may be applied after a
+// later Remove of the same key. AddChecked is applied synchronously after
+// the adds queued before it. Use Wait to drain the queue, e.g. in tests.
+// n <= 0 keeps adds synchronous. Default off.
+func WithAsyncAdd(n int) SafeOption {
+	return safeOption(func(s *SafeCache) {
+		s.queue = nil
+		if n > 0 {
+			s.queue = make(chan pendingAdd, n)
+		}
+	})
+}
+
+// WithGhostList remembers the keys of the last n capacity evictions. When
+// one of them is added again it is spared from its next eviction, since
+// evicting it the first time was evidently premature. n <= 0 picks a default
+// of a quarter of maxEntries, or 128 for caches bounded only by bytes.
+// Default off.
+func WithGhostList(n int) Option {
+	return func(c *Cache) {
+		c.ghostSize = n
+		if n <= 0 {
+			c.ghostSize = -1
+		}
+	}
+}
+
+// WithTinyLFU enables the TinyLFU admission filter with a sketch of about
+// counters counters. Default off, every new key is admitted.
+func WithTinyLFU(counters int) Option {
+	return func(c *Cache) {
+		c.admission = newTinyLFU(counters)
+	}
+}
@@ -0,0 +1,31 @@
+package lru
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewWithOptions(t *testing.T) {
+	keys := make([]string, 0)
+	callback := func(key string, value Value) {
+		keys = append(keys, key)
+	}
+	lru := NewWithOptions(WithMaxEntries(2), WithOnEvicted(callback), WithInitialCapacity(16))
+	lru.Add("k1", String("v1"))
+	lru.Add("k2", String("v2"))
+	lru.Add("k3", String("v3"))
+
+	if expect := []string{"k1"}; !reflect.DeepEqual(expect, keys) || lru.Len() != 2 {
+		t.Fatalf("expected %s to be evicted by entry limit but got %s", expect, keys)
+	}
+}
+
+func TestMaxBytesAndMaxEntries(t *testing.T) {
+	lru := NewWithOptions(WithMaxBytes(int64(len("k1v1k2v2"))), WithMaxEntries(3))
+	lru.Add("k1", String("v1"))
+	lru.Add("k2", String("v2"))
+	lru.Add("k3", String("v3"))
+	if _, ok := lru.Get("k1"); ok || lru.Len() != 2 {
+		t.Fatalf("byte limit should evict before the entry limit")
+	}
+}
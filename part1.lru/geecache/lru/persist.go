@@ -0,0 +1,58 @@
+package lru
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// record is the on-disk form of one entry written by Dump.
+type record struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Dump writes every live entry to w as a stream of JSON objects, oldest
+// first. Entries already expired or past maxAge are skipped, so Load does
+// not bring them back; expiry times themselves are not written.
+// Each value is encoded with json.Marshal, so the stored Value types must be
+// JSON-marshalable. ByteView values, which is also how entries compressed by
+// a codec come back, are written as base64 strings.
+func (c *Cache) Dump(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	// 从队首（最久未访问）写到队尾，Load 时按顺序 Add 即可还原访问顺序
+	for kv := c.ll.Back(); kv != nil; kv = c.ll.Prev(kv) {
+		if _, dead := c.dead(kv); dead {
+			continue
+		}
+		value, _ := c.valueOf(kv)
+		b, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		if err := enc.Encode(record{Key: kv.key, Value: b}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Load reads entries written by Dump and adds them to the cache in the same
+// recency order. decode turns the JSON form of a value back into a Value.
+// maxBytes is respected, so when the dump is larger than the cache the oldest
+// entries are evicted as usual.
+func (c *Cache) Load(r io.Reader, decode func([]byte) (Value, error)) error {
+	dec := json.NewDecoder(r)
+	for {
+		var rec record
+		if err := dec.Decode(&rec); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		value, err := decode(rec.Value)
+		if err != nil {
+			return err
+		}
+		c.Add(rec.Key, value)
+	}
+}
@@ -0,0 +1,148 @@
+package lru
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func decodeString(b []byte) (Value, error) {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, err
+	}
+	return String(s), nil
+}
+
+func TestDumpLoad(t *testing.T) {
+	lru := New(int64(0), nil)
+	lru.Add("k1", String("v1"))
+	lru.Add("k2", String("v2"))
+	lru.Add("k3", String("v3"))
+	lru.Get("k1")
+
+	var buf bytes.Buffer
+	if err := lru.Dump(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	keys := make([]string, 0)
+	callback := func(key string, value Value) {
+		keys = append(keys, key)
+	}
+	restored := New(int64(0), callback)
+	if err := restored.Load(&buf, decodeString); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := restored.Get("k2"); !ok || string(v.(String)) != "v2" {
+		t.Fatalf("restore k2=v2 failed")
+	}
+	// k2 刚被访问，淘汰顺序应为 k3, k1, k2
+	for restored.Len() > 0 {
+		restored.RemoveOldest()
+	}
+	if expect := []string{"k3", "k1", "k2"}; !reflect.DeepEqual(expect, keys) {
+		t.Fatalf("expect eviction order %s but got %s", expect, keys)
+	}
+}
+
+func TestLoadRespectsMaxBytes(t *testing.T) {
+	lru := New(int64(0), nil)
+	lru.Add("k1", String("v1"))
+	lru.Add("k2", String("v2"))
+	lru.Add("k3", String("v3"))
+
+	var buf bytes.Buffer
+	if err := lru.Dump(&buf); err != nil {
+		t.Fatal(err)
+	}
+	restored := New(int64(len("k1v1k2v2")), nil)
+	if err := restored.Load(&buf, decodeString); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := restored.Get("k1"); ok || restored.Len() != 2 {
+		t.Fatalf("expected oldest entry to be evicted on load")
+	}
+}
+
+func TestLoadEvictionOrder(t *testing.T) {
+	advance, restore := fakeClock()
+	defer restore()
+	var buf bytes.Buffer
+	src := New(int64(0), nil)
+	for _, k := range []string{"k1", "k2", "k3", "k4"} {
+		src.Add(k, String("v"))
+	}
+	src.Get("k2")
+	if err := src.Dump(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// 时钟不动，恢复出来的条目插入时间全部相同，只能按链表位置决定淘汰顺序
+	var keys []string
+	restored := NewWithOptions(WithMinRetention(time.Minute), WithDefaultTTL(time.Minute),
+		WithOnEvicted(func(key string, value Value) { keys = append(keys, key) }))
+	if err := restored.Load(&buf, decodeString); err != nil {
+		t.Fatal(err)
+	}
+	for restored.Len() > 2 {
+		restored.RemoveOldest()
+	}
+	if expect := []string{"k1", "k3"}; !reflect.DeepEqual(expect, keys) {
+		t.Fatalf("expect eviction order %s but got %s", expect, keys)
+	}
+	// 过期时间相同的条目按设置 TTL 的先后移除
+	restored.Add("k5", String("v"))
+	restored.Get("k4")
+	keys = nil
+	restored.UpdateTTL("k2", time.Minute)
+	advance(time.Hour)
+	restored.RemoveExpired()
+	if expect := []string{"k4", "k5", "k2"}; !reflect.DeepEqual(expect, keys) {
+		t.Fatalf("expect expiry order %s but got %s", expect, keys)
+	}
+}
+
+func TestDumpLoadBytes(t *testing.T) {
+	lru := New(int64(0), nil)
+	lru.SetBytes("k1", []byte{0, 1, 0xff})
+	var buf bytes.Buffer
+	if err := lru.Dump(&buf); err != nil {
+		t.Fatal(err)
+	}
+	restored := New(int64(0), nil)
+	err := restored.Load(&buf, func(b []byte) (Value, error) {
+		var v ByteView
+		err := json.Unmarshal(b, &v)
+		return v, err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, ok := restored.GetBytes("k1"); !ok || !bytes.Equal(b, []byte{0, 1, 0xff}) {
+		t.Fatalf("ByteView should survive Dump and Load, got %v", b)
+	}
+}
+
+func TestDumpSkipsExpired(t *testing.T) {
+	advance, restore := fakeClock()
+	defer restore()
+	lru := New(int64(0), nil)
+	lru.AddWithTTL("k1", String("v1"), time.Second)
+	lru.Add("k2", String("v2"))
+	advance(time.Second)
+	var buf bytes.Buffer
+	if err := lru.Dump(&buf); err != nil {
+		t.Fatal(err)
+	}
+	restored := New(int64(0), nil)
+	if err := restored.Load(&buf, decodeString); err != nil {
+		t.Fatal(err)
+	}
+	// 过期条目不应被 Load 复活
+	if _, ok := restored.Get("k1"); ok || restored.Len() != 1 {
+		t.Fatalf("expired entries should not be dumped")
+	}
+}
@@ -0,0 +1,26 @@
+package lru
+
+// Pin marks key as exempt from capacity eviction and reports whether the key
+// was present. A pinned entry keeps its place in the LRU order, so eviction
+// walks past every pinned entry at the cold end. If every remaining entry is
+// pinned the cache is allowed to stay over maxBytes/maxEntries until
+// something is unpinned or removed.
+// Pinned entries still expire by TTL or MaxAge and can be removed explicitly
+// with Remove.
+func (c *Cache) Pin(key string) bool {
+	return c.setPinned(key, true)
+}
+
+// Unpin makes key evictable again and reports whether the key was present.
+// It does not evict immediately; the next Add restores the budget.
+func (c *Cache) Unpin(key string) bool {
+	return c.setPinned(key, false)
+}
+
+func (c *Cache) setPinned(key string, pinned bool) bool {
+	if kv, ok := c.cache[key]; ok {
+		kv.pinned = pinned
+		return true
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package lru
+
+import "testing"
+
+func TestPin(t *testing.T) {
+	lru := New(int64(len("k1v1k2v2")), nil)
+	lru.Add("k1", String("v1"))
+	lru.Add("k2", String("v2"))
+	if !lru.Pin("k1") || lru.Pin("missing") {
+		t.Fatalf("Pin should report whether the key exists")
+	}
+	lru.Add("k3", String("v3"))
+	if _, ok := lru.Get("k1"); !ok {
+		t.Fatalf("pinned k1 should not be evicted")
+	}
+	if _, ok := lru.Get("k2"); ok {
+		t.Fatalf("k2 should be evicted instead of pinned k1")
+	}
+	if !lru.Remove("k1") {
+		t.Fatalf("pinned entries can be removed explicitly")
+	}
+}
+
+func TestPinOverBudget(t *testing.T) {
+	lru := New(int64(len("k1v1k2v2")), nil)
+	lru.Add("k1", String("v1"))
+	lru.Add("k2", String("v2"))
+	lru.Pin("k1")
+	lru.Pin("k2")
+	// 所有节点都被固定，允许暂时超出预算
+	lru.Add("k2", String("v2222"))
+	if lru.Len() != 2 || lru.Bytes() != int64(len("k1v1k2v2222")) {
+		t.Fatalf("expected pinned entries to exceed the budget, got %d entries", lru.Len())
+	}
+	lru.Unpin("k1")
+	lru.Add("k3", String("v3"))
+	if _, ok := lru.Get("k1"); ok {
+		t.Fatalf("unpinned k1 should be evicted on the next Add")
+	}
+	if _, ok := lru.Get("k2"); !ok {
+		t.Fatalf("pinned k2 should survive")
+	}
+}
+
+func TestSkippedKeepPlace(t *testing.T) {
+	busy := true
+	lru := NewWithOptions(WithMaxEntries(3), WithCanEvict(func(key string, value Value) bool {
+		return key != "busy" || !busy
+	}))
+	lru.Add("pinned", String("v"))
+	lru.Pin("pinned")
+	lru.Add("busy", String("v"))
+	lru.Add("k1", String("v"))
+	lru.Add("k2", String("v"))
+	if _, ok := lru.GetOpt("k1", false); ok {
+		t.Fatalf("k1 should be evicted in place of pinned and busy")
+	}
+	// 跳过不改变访问顺序：busy 解除否决后仍是最久未访问的
+	busy = false
+	lru.Unpin("pinned")
+	lru.Add("k3", String("v"))
+	lru.Add("k4", String("v"))
+	if lru.Len() != 3 {
+		t.Fatalf("expected 3 entries, got %d", lru.Len())
+	}
+	for _, key := range []string{"pinned", "busy"} {
+		if _, ok := lru.GetOpt(key, false); ok {
+			t.Fatalf("%s should be evicted first once it may be", key)
+		}
+	}
+}
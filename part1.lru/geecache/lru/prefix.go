@@ -0,0 +1,25 @@
+package lru
+
+import "strings"
+
+// RemovePrefix removes every entry whose key starts with prefix and returns
+// how many were removed. It scans all keys, so it costs O(Len) regardless of
+// how many match; callers doing this on every request should keep their own
+// index or use tags instead.
+func (c *Cache) RemovePrefix(prefix string) int {
+	var matched []*entry
+	for key, kv := range c.cache {
+		if strings.HasPrefix(key, prefix) {
+			matched = append(matched, kv)
+		}
+	}
+	n := 0
+	for _, kv := range matched {
+		// 回调可能已经删除或替换了后面的条目
+		if c.cache[kv.key] == kv {
+			c.removeElement(kv, ReasonRemoved)
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,20 @@
+package lru
+
+import "testing"
+
+func TestRemovePrefix(t *testing.T) {
+	lru := New(int64(0), nil)
+	lru.Add("user:1:profile", String("a"))
+	lru.Add("user:1:avatar", String("b"))
+	lru.Add("user:12:profile", String("c"))
+	lru.Add("user:2:profile", String("d"))
+	if n := lru.RemovePrefix("user:1:"); n != 2 {
+		t.Fatalf("expected 2 removals but got %d", n)
+	}
+	if _, ok := lru.Get("user:12:profile"); !ok || lru.Len() != 2 {
+		t.Fatalf("keys outside the prefix should be kept")
+	}
+	if n := lru.RemovePrefix("none"); n != 0 {
+		t.Fatalf("expected no removals but got %d", n)
+	}
+}
@@ -0,0 +1,299 @@
+package lru
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SafeCache wraps a Cache with a mutex so it can be shared by goroutines.
+// Len and Bytes read counters kept up to date by every mutation, so
+// monitoring goroutines can poll them without contending for the lock.
+// OnEvicted and the other hooks run with the lock held and must not call
+// back into the same SafeCache.
+type SafeCache struct {
+	mu     sync.Mutex
+	lru    *Cache
+	length int64 // 条目数，原子读写
+	nbytes int64 // 已使用内存，原子读写
+
+	measure   bool  // 是否统计等锁时间，由 WithLockStats 开启
+	locks     int64 // 加锁次数
+	waitNanos int64 // 累计等锁时间
+	maxWait   int64 // 最长一次等锁时间
+
+	// 以下字段仅在 WithAsyncAdd 时使用
+	queue     chan pendingAdd // 待后台写入的 Add
+	drained   chan struct{}   // 后台 goroutine 退出时关闭
+	qmu       sync.RWMutex    // 发送时持读锁，Close 关闭队列时持写锁
+	closed    bool            // 队列已关闭，由 qmu 保护
+	closeOnce sync.Once
+}
+
+// LockStats describes how long callers waited for a SafeCache's lock.
+type LockStats struct {
+	Acquisitions int64
+	TotalWait    time.Duration
+	MaxWait      time.Duration
+}
+
+// AvgWait returns the mean time spent waiting per acquisition.
+func (s LockStats) AvgWait() time.Duration {
+	if s.Acquisitions == 0 {
+		return 0
+	}
+	return s.TotalWait / time.Duration(s.Acquisitions)
+}
+
+// NewSafe creates a concurrency-safe cache configured by opts. With
+// WithAsyncAdd it also starts a goroutine that applies queued adds; call
+// Close to stop it.
+func NewSafe(opts ...SafeOption) *SafeCache {
+	// 先用 Cache 的选项建好底层缓存，再应用 SafeCache 自己的选项
+	var cacheOpts []Option
+	var safeOpts []SafeOption
+	for _, opt := range opts {
+		if o, ok := opt.(Option); ok {
+			cacheOpts = append(cacheOpts, o)
+		} else {
+			safeOpts = append(safeOpts, opt)
+		}
+	}
+	s := &SafeCache{lru: NewWithOptions(cacheOpts...)}
+	for _, opt := range safeOpts {
+		opt.applySafe(s)
+	}
+	if s.queue != nil {
+		s.drained = make(chan struct{})
+		go s.drain()
+	}
+	return s
+}
+
+// lock acquires s.mu, timing the wait when lock stats are enabled.
+func (s *SafeCache) lock() {
+	if !s.measure {
+		s.mu.Lock()
+		return
+	}
+	start := time.Now()
+	s.mu.Lock()
+	d := int64(time.Since(start))
+	atomic.AddInt64(&s.locks, 1)
+	atomic.AddInt64(&s.waitNanos, d)
+	// 已持有锁，读改写 maxWait 不会和其他写者冲突
+	if d > atomic.LoadInt64(&s.maxWait) {
+		atomic.StoreInt64(&s.maxWait, d)
+	}
+}
+
+// Stats returns the lock wait statistics collected so far. They stay zero
+// unless the cache was created WithLockStats. A high average wait suggests
+// the cache is contended enough to be worth sharding.
+func (s *SafeCache) Stats() LockStats {
+	return LockStats{
+		Acquisitions: atomic.LoadInt64(&s.locks),
+		TotalWait:    time.Duration(atomic.LoadInt64(&s.waitNanos)),
+		MaxWait:      time.Duration(atomic.LoadInt64(&s.maxWait)),
+	}
+}
+
+// sync publishes the wrapped cache's size; callers must hold s.mu.
+func (s *SafeCache) sync() {
+	atomic.StoreInt64(&s.length, int64(s.lru.Len()))
+	atomic.StoreInt64(&s.nbytes, s.lru.Bytes())
+}
+
+// Add adds a value to the cache. With WithAsyncAdd the value is only queued
+// and may not be visible to Get until the queue drains; after Close it is
+// applied synchronously instead.
+func (s *SafeCache) Add(key string, value Value) {
+	if s.queue != nil && s.enqueue(key, value, s.lru.expiry(0)) {
+		return
+	}
+	s.lock()
+	defer s.mu.Unlock()
+	s.lru.Add(key, value)
+	s.sync()
+}
+
+// AddChecked is like Add but returns the error from Validate. It is always
+// applied synchronously, even WithAsyncAdd; it first waits for the adds
+// already queued so none of them can overwrite it afterwards.
+func (s *SafeCache) AddChecked(key string, value Value) error {
+	// 先排空队列，否则之前排队的同 key 写入可能在之后覆盖这次写入
+	s.Wait()
+	s.lock()
+	defer s.mu.Unlock()
+	err := s.lru.AddChecked(key, value)
+	s.sync()
+	return err
+}
+
+// AddWithTTL adds a value that expires after ttl. Like Add it is queued
+// WithAsyncAdd; the ttl counts from the call, not from when it is applied.
+func (s *SafeCache) AddWithTTL(key string, value Value, ttl time.Duration) {
+	if s.queue != nil && s.enqueue(key, value, s.lru.expiry(ttl)) {
+		return
+	}
+	s.lock()
+	defer s.mu.Unlock()
+	s.lru.AddWithTTL(key, value, ttl)
+	s.sync()
+}
+
+func (s *SafeCache) addWithTTLChecked(key string, value Value, ttl time.Duration) error {
+	s.Wait()
+	s.lock()
+	defer s.mu.Unlock()
+	_, err := s.lru.addChecked(key, value, s.lru.expiry(ttl))
+	s.sync()
+	return err
+}
+
+// UpdateTTL changes when key expires and reports whether it was present.
+func (s *SafeCache) UpdateTTL(key string, ttl time.Duration) bool {
+	s.lock()
+	defer s.mu.Unlock()
+	ok := s.lru.UpdateTTL(key, ttl)
+	s.sync()
+	return ok
+}
+
+// EntryInfo returns when key was added and last accessed.
+func (s *SafeCache) EntryInfo(key string) (created, accessed time.Time, ok bool) {
+	s.lock()
+	defer s.mu.Unlock()
+	created, accessed, ok = s.lru.EntryInfo(key)
+	s.sync()
+	return
+}
+
+// SetBytes adds a copy of b as a ByteView.
+func (s *SafeCache) SetBytes(key string, b []byte) {
+	s.Add(key, NewByteView(b))
+}
+
+// GetBytes returns a copy of the bytes stored for key as a ByteView.
+func (s *SafeCache) GetBytes(key string) ([]byte, bool) {
+	if v, ok := s.Get(key); ok {
+		if bv, ok := v.(ByteView); ok {
+			return bv.ByteSlice(), true
+		}
+	}
+	return nil, false
+}
+
+// Get look ups a key's value
+func (s *SafeCache) Get(key string) (value Value, ok bool) {
+	s.lock()
+	defer s.mu.Unlock()
+	value, ok = s.lru.Get(key)
+	// Get 可能顺带删除过期条目
+	s.sync()
+	return
+}
+
+// GetWithExpiry is like Get but also returns the entry's expiry time.
+func (s *SafeCache) GetWithExpiry(key string) (value Value, expiresAt time.Time, ok bool) {
+	s.lock()
+	defer s.mu.Unlock()
+	value, expiresAt, ok = s.lru.GetWithExpiry(key)
+	s.sync()
+	return
+}
+
+// Remove removes key from the cache and reports whether it was present.
+func (s *SafeCache) Remove(key string) bool {
+	s.lock()
+	defer s.mu.Unlock()
+	ok := s.lru.Remove(key)
+	s.sync()
+	return ok
+}
+
+// SetMaxBytes changes the byte limit, evicting at once when it is lowered.
+// It is atomic with respect to concurrent Add and Get.
+func (s *SafeCache) SetMaxBytes(maxBytes int64) {
+	s.lock()
+	defer s.mu.Unlock()
+	s.lru.SetMaxBytes(maxBytes)
+	s.sync()
+}
+
+// GetMaxBytes returns the byte limit; 0 means unlimited.
+func (s *SafeCache) GetMaxBytes() int64 {
+	s.lock()
+	defer s.mu.Unlock()
+	return s.lru.GetMaxBytes()
+}
+
+// Trim evicts entries until at most targetBytes are in use.
+func (s *SafeCache) Trim(targetBytes int64) int {
+	s.lock()
+	defer s.mu.Unlock()
+	n := s.lru.Trim(targetBytes)
+	s.sync()
+	return n
+}
+
+// RemoveExpired removes every entry whose TTL has passed and returns how
+// many were removed. It is meant to be called periodically by a reaper.
+func (s *SafeCache) RemoveExpired() int {
+	s.lock()
+	defer s.mu.Unlock()
+	n := s.lru.RemoveExpired()
+	s.sync()
+	return n
+}
+
+// RemovePrefix removes every entry whose key starts with prefix.
+func (s *SafeCache) RemovePrefix(prefix string) int {
+	s.lock()
+	defer s.mu.Unlock()
+	n := s.lru.RemovePrefix(prefix)
+	s.sync()
+	return n
+}
+
+// InvalidateTag removes every entry carrying tag.
+func (s *SafeCache) InvalidateTag(tag string) int {
+	s.lock()
+	defer s.mu.Unlock()
+	n := s.lru.InvalidateTag(tag)
+	s.sync()
+	return n
+}
+
+// Compact rebuilds the wrapped cache's map to release memory after churn.
+func (s *SafeCache) Compact() {
+	s.lock()
+	defer s.mu.Unlock()
+	s.lru.Compact()
+}
+
+// Clear removes every entry.
+func (s *SafeCache) Clear() {
+	s.lock()
+	defer s.mu.Unlock()
+	s.lru.Clear()
+	s.sync()
+}
+
+// RemoveOldest removes the oldest item
+func (s *SafeCache) RemoveOldest() {
+	s.lock()
+	defer s.mu.Unlock()
+	s.lru.RemoveOldest()
+	s.sync()
+}
+
+// Len the number of cache entries, read without taking the lock.
+func (s *SafeCache) Len() int {
+	return int(atomic.LoadInt64(&s.length))
+}
+
+// Bytes returns the number of bytes in use, read without taking the lock.
+func (s *SafeCache) Bytes() int64 {
+	return atomic.LoadInt64(&s.nbytes)
+}
@@ -0,0 +1,88 @@
+package lru
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestSafeConcurrent(t *testing.T) {
+	cache := NewSafe(WithMaxEntries(50))
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				key := strconv.Itoa(i*1000 + j)
+				cache.Add(key, String("v"))
+				cache.Get(key)
+				_ = cache.Len()
+			}
+		}(i)
+	}
+	wg.Wait()
+	if cache.Len() != 50 {
+		t.Fatalf("expected 50 entries but got %d", cache.Len())
+	}
+}
+
+func TestSafeLenBytes(t *testing.T) {
+	cache := NewSafe()
+	cache.Add("key1", String("1234"))
+	cache.Add("key2", String("5678"))
+	if cache.Len() != 2 || cache.Bytes() != int64(len("key11234key25678")) {
+		t.Fatalf("unexpected Len %d / Bytes %d", cache.Len(), cache.Bytes())
+	}
+	cache.RemoveOldest()
+	if cache.Len() != 1 || cache.Bytes() != int64(len("key25678")) {
+		t.Fatalf("unexpected Len %d / Bytes %d after RemoveOldest", cache.Len(), cache.Bytes())
+	}
+}
+
+func TestSafeLockStats(t *testing.T) {
+	cache := NewSafe(WithLockStats())
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				cache.Add("k", String("v"))
+			}
+		}()
+	}
+	wg.Wait()
+	stats := cache.Stats()
+	if stats.Acquisitions != 400 || stats.MaxWait > stats.TotalWait {
+		t.Fatalf("unexpected lock stats %+v", stats)
+	}
+	if NewSafe().Stats() != (LockStats{}) {
+		t.Fatalf("lock stats should stay zero unless enabled")
+	}
+}
+
+func TestSafeSetMaxBytesConcurrent(t *testing.T) {
+	cache := NewSafe(WithMaxBytes(1000))
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 500; j++ {
+				cache.Add(strconv.Itoa(i*1000+j), String("value"))
+			}
+		}(i)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < 200; j++ {
+			cache.SetMaxBytes(int64(100 + j%5*100))
+		}
+	}()
+	wg.Wait()
+	if max := cache.GetMaxBytes(); cache.Bytes() > max {
+		t.Fatalf("expected at most %d bytes but got %d", max, cache.Bytes())
+	}
+}
@@ -0,0 +1,151 @@
+package lru
+
+import "math/rand"
+
+// DefaultSamples is the number of entries SampledCache compares per eviction
+// when none is given.
+const DefaultSamples = 5
+
+// SampledCache is an approximate LRU cache in the style of Redis: instead of
+// a linked list it keeps entries in a slice and, to evict, samples a few at
+// random and removes the least recently used of those.
+//
+// Compared with Cache it saves two pointers per entry and a list update on
+// every Get, at the price of sometimes evicting an entry that is not the
+// globally oldest. With 5 samples the victim is usually among the oldest few
+// percent; larger sample counts approach exact LRU at a higher eviction cost.
+// It is not safe for concurrent access.
+type SampledCache struct {
+	maxBytes int64
+	nbytes   int64
+	samples  int
+	clock    uint64 // 逻辑时钟，每次访问加一，代替真实时间戳
+	entries  []*sampledEntry
+	index    map[string]int // key 到 entries 下标
+	rnd      *rand.Rand
+	// optional and executed when an entry is purged.
+	OnEvicted func(key string, value Value)
+}
+
+type sampledEntry struct {
+	key        string
+	value      Value
+	size       int64
+	lastAccess uint64
+}
+
+var _ Interface = (*SampledCache)(nil)
+
+// NewSampled creates a SampledCache comparing samples entries per eviction;
+// samples <= 0 uses DefaultSamples.
+func NewSampled(maxBytes int64, samples int, onEvicted func(string, Value)) *SampledCache {
+	if samples <= 0 {
+		samples = DefaultSamples
+	}
+	return &SampledCache{
+		maxBytes:  maxBytes,
+		samples:   samples,
+		index:     make(map[string]int),
+		rnd:       rand.New(rand.NewSource(1)),
+		OnEvicted: onEvicted,
+	}
+}
+
+// Add adds a value to the cache. Like Cache.Add it silently drops nil
+// values.
+func (c *SampledCache) Add(key string, value Value) {
+	if value == nil {
+		return
+	}
+	c.clock++
+	size := int64(len(key)) + int64(value.Len())
+	if i, ok := c.index[key]; ok {
+		e := c.entries[i]
+		c.nbytes += size - e.size
+		e.value, e.size, e.lastAccess = value, size, c.clock
+	} else {
+		c.index[key] = len(c.entries)
+		c.entries = append(c.entries, &sampledEntry{key: key, value: value, size: size, lastAccess: c.clock})
+		c.nbytes += size
+	}
+	for c.maxBytes != 0 && c.maxBytes < c.nbytes {
+		c.RemoveOldest()
+	}
+}
+
+// Get look ups a key's value
+func (c *SampledCache) Get(key string) (value Value, ok bool) {
+	if i, ok := c.index[key]; ok {
+		c.clock++
+		e := c.entries[i]
+		e.lastAccess = c.clock
+		return e.value, true
+	}
+	return
+}
+
+// RemoveOldest evicts the least recently used of a random sample of entries.
+func (c *SampledCache) RemoveOldest() {
+	n := len(c.entries)
+	if n == 0 {
+		return
+	}
+	victim := -1
+	if n <= c.samples {
+		// 条目不多时直接全部比较，等价于精确 LRU
+		for i, e := range c.entries {
+			if victim < 0 || e.lastAccess < c.entries[victim].lastAccess {
+				victim = i
+			}
+		}
+	} else {
+		for s := 0; s < c.samples; s++ {
+			i := c.rnd.Intn(n)
+			if victim < 0 || c.entries[i].lastAccess < c.entries[victim].lastAccess {
+				victim = i
+			}
+		}
+	}
+	c.removeAt(victim)
+}
+
+// Remove removes key from the cache and reports whether it was present.
+func (c *SampledCache) Remove(key string) bool {
+	i, ok := c.index[key]
+	if ok {
+		c.removeAt(i)
+	}
+	return ok
+}
+
+// removeAt deletes entries[i] by moving the last entry into its slot.
+func (c *SampledCache) removeAt(i int) {
+	e := c.entries[i]
+	last := len(c.entries) - 1
+	c.entries[i] = c.entries[last]
+	c.index[c.entries[i].key] = i
+	c.entries[last] = nil
+	c.entries = c.entries[:last]
+	delete(c.index, e.key)
+	c.nbytes -= e.size
+	if c.OnEvicted != nil {
+		c.OnEvicted(e.key, e.value)
+	}
+}
+
+// Clear removes every entry, calling OnEvicted for each one.
+func (c *SampledCache) Clear() {
+	for len(c.entries) > 0 {
+		c.removeAt(len(c.entries) - 1)
+	}
+}
+
+// Len the number of cache entries
+func (c *SampledCache) Len() int {
+	return len(c.entries)
+}
+
+// Bytes returns the number of bytes currently accounted to the cache.
+func (c *SampledCache) Bytes() int64 {
+	return c.nbytes
+}
@@ -0,0 +1,60 @@
+package lru
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestSampledExactWhenSmall(t *testing.T) {
+	lru := NewSampled(int64(len("k1v1k2v2k3v3")), 5, nil)
+	lru.Add("k1", String("v1"))
+	lru.Add("k2", String("v2"))
+	lru.Add("k3", String("v3"))
+	lru.Get("k1")
+	lru.Add("k4", String("v4"))
+	if _, ok := lru.Get("k2"); ok || lru.Len() != 3 {
+		t.Fatalf("with fewer entries than samples eviction should be exact LRU")
+	}
+	if !lru.Remove("k1") || lru.Bytes() != int64(len("k3v3k4v4")) {
+		t.Fatalf("Remove should keep byte accounting consistent")
+	}
+	lru.Clear()
+	if lru.Len() != 0 || lru.Bytes() != 0 {
+		t.Fatalf("Clear should remove every entry")
+	}
+}
+
+func TestSampledApproximate(t *testing.T) {
+	const n = 1000
+	lru := NewSampled(0, 0, nil)
+	for i := 0; i < n; i++ {
+		lru.Add(strconv.Itoa(i), String("v"))
+	}
+	// 访问后一半，使前一半成为冷数据
+	for i := n / 2; i < n; i++ {
+		lru.Get(strconv.Itoa(i))
+	}
+	hot := 0
+	lru.OnEvicted = func(key string, value Value) {
+		if i, _ := strconv.Atoi(key); i >= n/2 {
+			hot++
+		}
+	}
+	for i := 0; i < n/4; i++ {
+		lru.RemoveOldest()
+	}
+	// 5 个样本全是热数据的概率开始时约 1/32，冷数据减少后升高，允许 10% 误差
+	if hot > n/4/10 {
+		t.Fatalf("too many recently used entries evicted: %d of %d", hot, n/4)
+	}
+}
+
+func TestSampledNil(t *testing.T) {
+	lru := NewSampled(int64(0), 0, nil)
+	lru.Add("k1", String("v1"))
+	lru.Add("k1", nil)
+	lru.Add("k2", nil)
+	if v, ok := lru.Get("k1"); !ok || string(v.(String)) != "v1" || lru.Len() != 1 {
+		t.Fatalf("nil values should be dropped without touching existing entries")
+	}
+}
@@ -0,0 +1,62 @@
+package lru
+
+// AddWithTags adds a value and associates it with tags, so that it can be
+// dropped together with other entries by InvalidateTag. The tags stay with
+// the entry until it is replaced: a later plain Add of the same key clears
+// them, AddWithTags replaces them.
+func (c *Cache) AddWithTags(key string, value Value, tags ...string) error {
+	kv, err := c.addChecked(key, value, c.expiry(0))
+	if kv == nil || len(tags) == 0 {
+		return err
+	}
+	kv.tags = append([]string(nil), tags...)
+	c.tag(kv)
+	return nil
+}
+
+// tag registers kv under each of its tags.
+func (c *Cache) tag(kv *entry) {
+	if c.tags == nil {
+		c.tags = make(map[string]map[string]struct{})
+	}
+	for _, tag := range kv.tags {
+		keys := c.tags[tag]
+		if keys == nil {
+			keys = make(map[string]struct{})
+			c.tags[tag] = keys
+		}
+		keys[kv.key] = struct{}{}
+	}
+}
+
+// InvalidateTag removes every entry carrying tag and returns how many were
+// removed.
+func (c *Cache) InvalidateTag(tag string) int {
+	keys := c.tags[tag]
+	matched := make([]*entry, 0, len(keys))
+	for key := range keys {
+		matched = append(matched, c.cache[key])
+	}
+	n := 0
+	for _, kv := range matched {
+		// 回调可能已经删除或替换了后面的条目
+		if c.cache[kv.key] == kv {
+			c.removeElement(kv, ReasonRemoved)
+			n++
+		}
+	}
+	return n
+}
+
+// untag drops kv from the tag index, removing tags that become empty so
+// the index does not leak.
+func (c *Cache) untag(kv *entry) {
+	for _, tag := range kv.tags {
+		keys := c.tags[tag]
+		delete(keys, kv.key)
+		if len(keys) == 0 {
+			delete(c.tags, tag)
+		}
+	}
+	kv.tags = nil
+}
@@ -0,0 +1,47 @@
+package lru
+
+import "testing"
+
+func TestInvalidateTag(t *testing.T) {
+	lru := New(int64(0), nil)
+	lru.AddWithTags("k1", String("v1"), "homepage", "news")
+	lru.AddWithTags("k2", String("v2"), "homepage")
+	lru.AddWithTags("k3", String("v3"), "news")
+	lru.Add("k4", String("v4"))
+
+	if n := lru.InvalidateTag("homepage"); n != 2 {
+		t.Fatalf("expected 2 removals but got %d", n)
+	}
+	if lru.Len() != 2 {
+		t.Fatalf("expected k3 and k4 to remain")
+	}
+	if _, ok := lru.tags["homepage"]; ok {
+		t.Fatalf("empty tags should be dropped from the index")
+	}
+	if keys := lru.tags["news"]; len(keys) != 1 {
+		t.Fatalf("removed k1 should be dropped from its other tags, got %v", keys)
+	}
+}
+
+func TestTagsCleanedOnEvict(t *testing.T) {
+	lru := New(int64(len("k1v1")), nil)
+	lru.AddWithTags("k1", String("v1"), "a")
+	lru.Add("k2", String("v2"))
+	if len(lru.tags) != 0 {
+		t.Fatalf("eviction should clean up the tag index, got %v", lru.tags)
+	}
+	lru.AddWithTags("k2", String("v2"), "b")
+	lru.Add("k2", String("v2"))
+	if lru.InvalidateTag("b") != 0 || lru.Len() != 1 {
+		t.Fatalf("a plain Add should clear the entry's tags")
+	}
+}
+
+func TestCloneKeepsTags(t *testing.T) {
+	lru := New(int64(0), nil)
+	lru.AddWithTags("k1", String("v1"), "a")
+	clone := lru.Clone()
+	if clone.InvalidateTag("a") != 1 || lru.Len() != 1 {
+		t.Fatalf("clone should have its own tag index")
+	}
+}
@@ -0,0 +1,144 @@
+package lru
+
+// Tiered composes a small, fast L1 cache with a larger L2. Entries live in
+// exactly one tier: an entry evicted from L1 for capacity is demoted to L2
+// with its remaining TTL, and an L2 hit is promoted back into L1. Entries
+// removed, expired or stale in L1 are dropped rather than demoted.
+//
+// Tiered takes over the OnEvicted callbacks of both caches; set its own
+// OnEvicted field instead, which fires once per entry when it leaves the
+// last tier. It is not safe for concurrent access.
+type Tiered struct {
+	// optional and executed when an entry leaves both tiers.
+	OnEvicted func(key string, value Value)
+
+	l1, l2 *Cache
+	stats  TierStats
+}
+
+// TierStats counts where a Tiered cache's lookups were served from and how
+// entries moved between the tiers.
+type TierStats struct {
+	L1Hits     int64
+	L2Hits     int64
+	Misses     int64
+	Promotions int64 // L2 命中后移入 L1 的次数
+	Demotions  int64 // L1 淘汰后降级到 L2 的次数
+}
+
+// NewTiered creates a Tiered cache over l1 and l2, which must be distinct
+// and should not be used directly afterwards.
+func NewTiered(l1, l2 *Cache) *Tiered {
+	t := &Tiered{l1: l1, l2: l2}
+	l1.OnEvicted, l2.OnEvicted = nil, nil
+	l1.onRemove = t.removedL1
+	l2.onRemove = t.removedL2
+	return t
+}
+
+// removedL1 demotes entries evicted from L1 for capacity and reports the
+// others as gone. A demoted entry has not left the cache, so it reports
+// true to keep L1's observer and event stream from seeing an eviction.
+func (t *Tiered) removedL1(kv *entry, reason EvictionReason) bool {
+	value, ok := t.l1.valueOf(kv)
+	if !ok {
+		return false
+	}
+	if reason == ReasonCapacity {
+		// 降级引起的 L2 淘汰是真正的淘汰，照常回调
+		if _, err := t.l2.addChecked(kv.key, value, kv.expire); err == nil {
+			t.stats.Demotions++
+			return true
+		}
+		// L2 放不下，条目彻底离开
+	}
+	if t.OnEvicted != nil {
+		t.OnEvicted(kv.key, value)
+	}
+	return false
+}
+
+func (t *Tiered) removedL2(kv *entry, reason EvictionReason) bool {
+	if t.OnEvicted == nil {
+		return false
+	}
+	if value, ok := t.l2.valueOf(kv); ok {
+		t.OnEvicted(kv.key, value)
+	}
+	return false
+}
+
+// Add adds a value to L1, replacing any copy of key in either tier. A nil
+// value or one L1's Validate rejects leaves both tiers unchanged. If L1 does
+// not keep the value, for instance because it is too large, the old copy is
+// removed anyway and reported to OnEvicted.
+func (t *Tiered) Add(key string, value Value) {
+	if t.l1.check(key, value) != nil {
+		return
+	}
+	// L2 中的副本被新值取代，不是淘汰，不通知观察者
+	stale := t.l2.cache[key]
+	if stale != nil {
+		t.l2.unlink(stale)
+	}
+	if kv, _ := t.l1.add(key, value, t.l1.expiry(0)); kv == nil && stale != nil {
+		t.removedL2(stale, ReasonRemoved)
+	}
+}
+
+// Get looks up key in L1, then in L2. An L2 hit is moved into L1, which
+// may demote L1's least recently used entry in exchange.
+func (t *Tiered) Get(key string) (value Value, ok bool) {
+	if value, ok = t.l1.Get(key); ok {
+		t.stats.L1Hits++
+		return
+	}
+	kv := t.l2.lookup(key, true)
+	if kv == nil {
+		t.stats.Misses++
+		return nil, false
+	}
+	if value, ok = t.l2.valueOf(kv); !ok {
+		t.stats.Misses++
+		return nil, false
+	}
+	t.stats.L2Hits++
+	// 先从 L2 移除再放入 L1：放入 L1 引起的降级可能挤占 L2 的空间。
+	// 升级不是删除，不通知 L2 的观察者
+	t.l2.unlink(kv)
+	if kept, _ := t.l1.addChecked(key, value, kv.expire); kept != nil {
+		t.stats.Promotions++
+	} else if t.l2.cache[key] == nil {
+		// L1 拒绝了它且没有降级回来，放回 L2
+		t.l2.addChecked(key, value, kv.expire)
+	}
+	return value, true
+}
+
+// Remove removes key from whichever tier holds it and reports whether it
+// was present.
+func (t *Tiered) Remove(key string) bool {
+	return t.l1.Remove(key) || t.l2.Remove(key)
+}
+
+// Clear removes every entry from both tiers.
+func (t *Tiered) Clear() {
+	t.l1.Clear()
+	t.l2.Clear()
+}
+
+// Len returns the number of entries in both tiers.
+func (t *Tiered) Len() int {
+	return t.l1.Len() + t.l2.Len()
+}
+
+// Bytes returns the number of bytes used by both tiers.
+func (t *Tiered) Bytes() int64 {
+	return t.l1.Bytes() + t.l2.Bytes()
+}
+
+// Stats returns the lookup and movement counts so far. Per-tier sizes are
+// available from the underlying caches.
+func (t *Tiered) Stats() TierStats {
+	return t.stats
+}
@@ -0,0 +1,118 @@
+package lru
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTiered(t *testing.T) {
+	var evicted []string
+	tc := NewTiered(NewWithOptions(WithMaxEntries(2)), NewWithOptions(WithMaxEntries(2)))
+	tc.OnEvicted = func(key string, value Value) { evicted = append(evicted, key) }
+	for _, k := range []string{"k1", "k2", "k3", "k4"} {
+		tc.Add(k, String(k))
+	}
+	// k1、k2 被降级到 L2，没有离开缓存
+	if tc.Len() != 4 || len(evicted) != 0 {
+		t.Fatalf("demotion should not count as eviction, len %d evicted %v", tc.Len(), evicted)
+	}
+	if v, ok := tc.Get("k1"); !ok || v.(String) != "k1" {
+		t.Fatalf("L2 hit k1 failed")
+	}
+	// k1 升回 L1，换下 k3
+	if _, ok := tc.l1.Get("k1"); !ok || tc.l2.Len() != 2 {
+		t.Fatalf("k1 should be promoted into L1")
+	}
+	tc.Add("k5", String("k5"))
+	if !reflect.DeepEqual(evicted, []string{"k2"}) || tc.Len() != 4 {
+		t.Fatalf("k2 should leave from L2, got %v", evicted)
+	}
+	tc.Get("k1")
+	tc.Get("missing")
+	want := TierStats{L1Hits: 1, L2Hits: 1, Misses: 1, Promotions: 1, Demotions: 4}
+	if got := tc.Stats(); got != want {
+		t.Fatalf("got stats %+v, want %+v", got, want)
+	}
+	if !tc.Remove("k3") || tc.Remove("k3") || !reflect.DeepEqual(evicted, []string{"k2", "k3"}) {
+		t.Fatalf("Remove should drop k3 from L2 once, got %v", evicted)
+	}
+}
+
+func TestTieredAddReplacesL2(t *testing.T) {
+	var evicted []string
+	tc := NewTiered(NewWithOptions(WithMaxEntries(1)), New(int64(0), nil))
+	tc.OnEvicted = func(key string, value Value) { evicted = append(evicted, key) }
+	tc.Add("k1", String("old"))
+	tc.Add("k2", String("v"))
+	tc.Add("k1", String("new"))
+	if v, _ := tc.Get("k1"); v.(String) != "new" || tc.Len() != 2 || len(evicted) != 0 {
+		t.Fatalf("Add should replace the L2 copy without an eviction, got %v %v", v, evicted)
+	}
+}
+
+func TestTieredKeepsTTL(t *testing.T) {
+	advance, restore := fakeClock()
+	defer restore()
+	var evicted []string
+	tc := NewTiered(NewWithOptions(WithMaxEntries(1), WithDefaultTTL(time.Minute)), New(int64(0), nil))
+	tc.OnEvicted = func(key string, value Value) { evicted = append(evicted, key) }
+	tc.Add("k1", String("1"))
+	tc.Add("k2", String("2"))
+	advance(time.Minute)
+	// 降级到 L2 的 k1 保留原来的过期时间；L1 中过期的 k2 不会降级
+	if _, ok := tc.Get("k1"); ok {
+		t.Fatalf("k1 should expire in L2")
+	}
+	if _, ok := tc.Get("k2"); ok || tc.Len() != 0 {
+		t.Fatalf("k2 should expire in L1")
+	}
+	if !reflect.DeepEqual(evicted, []string{"k1", "k2"}) {
+		t.Fatalf("each expired entry should be reported once, got %v", evicted)
+	}
+}
+
+func TestTieredAddRejected(t *testing.T) {
+	var evicted []string
+	tc := NewTiered(NewWithOptions(WithMaxEntries(1), WithMaxBytes(8)), New(int64(0), nil))
+	tc.OnEvicted = func(key string, value Value) { evicted = append(evicted, key+"="+string(value.(String))) }
+	tc.Add("k1", String("old"))
+	tc.Add("k2", String("v"))
+	tc.Add("k1", nil)
+	if v, ok := tc.Get("k1"); !ok || v.(String) != "old" {
+		t.Fatalf("an invalid value should keep the L2 copy, got %v", v)
+	}
+	// k1 现在在 L1，k2 在 L2
+	tc.Add("k1", String("too large"))
+	tc.Add("k2", String("too large"))
+	if tc.Len() != 0 || !reflect.DeepEqual(evicted, []string{"k1=old", "k2=v"}) {
+		t.Fatalf("a rejected value should drop the old copy in either tier, got %v", evicted)
+	}
+}
+
+func TestTieredPromotionNotRemoval(t *testing.T) {
+	r1, r2 := &recorder{}, &recorder{}
+	tc := NewTiered(NewWithOptions(WithMaxEntries(1), WithObserver(r1)), NewWithOptions(WithObserver(r2)))
+	events1, events2 := tc.l1.Events(), tc.l2.Events()
+	tc.Add("k1", String("1"))
+	tc.Add("k2", String("2"))
+	tc.Get("k1")
+	tc.Add("k2", String("22"))
+	// 降级、升级和覆盖 L2 副本都不是条目离开缓存
+	for name, r := range map[string]*recorder{"L1": r1, "L2": r2} {
+		for _, op := range r.ops {
+			if strings.HasPrefix(op, "evict") {
+				t.Fatalf("moving between tiers was reported to %s's observer: %v", name, r.ops)
+			}
+		}
+	}
+	if len(events1) != 0 || len(events2) != 0 {
+		t.Fatalf("moving between tiers emitted %d L1 and %d L2 events", len(events1), len(events2))
+	}
+	// 真正离开缓存的条目照常通知
+	tc.Remove("k2")
+	if len(events1) != 1 || r1.ops[len(r1.ops)-1] != "evict k2 removed" {
+		t.Fatalf("a removal from L1 should still be reported, got %v", r1.ops)
+	}
+}
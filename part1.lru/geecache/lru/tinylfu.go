@@ -0,0 +1,105 @@
+package lru
+
+import "hash/fnv"
+
+// cmDepth is the number of rows in the count-min sketch.
+const cmDepth = 4
+
+// cmMax is the saturation value of a sketch counter.
+const cmMax = 15
+
+// tinyLFU is a frequency-based admission filter.
+// 用 count-min sketch 估计 key 的访问频率，淘汰前比较新 key 与待淘汰 key 的频率，
+// 只有新 key 更“热”时才允许它挤掉旧 key，避免只访问一次的 key 冲掉热点数据。
+type tinyLFU struct {
+	rows    [cmDepth][]uint8
+	mask    uint64
+	seeds   [cmDepth]uint64
+	samples int // 累计记录次数，达到 reset 时所有计数减半（老化）
+	reset   int
+}
+
+// newTinyLFU creates a sketch with at least counters counters per row.
+func newTinyLFU(counters int) *tinyLFU {
+	if counters < 16 {
+		counters = 16
+	}
+	// 每行长度取 2 的幂，方便用位运算取模
+	width := 1
+	for width < counters {
+		width <<= 1
+	}
+	t := &tinyLFU{
+		mask:  uint64(width - 1),
+		seeds: [cmDepth]uint64{0x9e3779b97f4a7c15, 0xbf58476d1ce4e5b9, 0x94d049bb133111eb, 0x2545f4914f6cdd1d},
+		reset: 10 * width,
+	}
+	for i := range t.rows {
+		t.rows[i] = make([]uint8, width)
+	}
+	return t
+}
+
+func (t *tinyLFU) hash(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return h.Sum64()
+}
+
+func (t *tinyLFU) index(h uint64, i int) uint64 {
+	h ^= t.seeds[i]
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	return h & t.mask
+}
+
+// record increments the frequency estimate of key.
+func (t *tinyLFU) record(key string) {
+	h := t.hash(key)
+	for i := range t.rows {
+		idx := t.index(h, i)
+		if t.rows[i][idx] < cmMax {
+			t.rows[i][idx]++
+		}
+	}
+	t.samples++
+	if t.samples >= t.reset {
+		t.age()
+	}
+}
+
+// estimate returns the frequency estimate of key.
+func (t *tinyLFU) estimate(key string) uint8 {
+	h := t.hash(key)
+	min := uint8(cmMax)
+	for i := range t.rows {
+		if v := t.rows[i][t.index(h, i)]; v < min {
+			min = v
+		}
+	}
+	return min
+}
+
+// age halves every counter so old popularity fades over time.
+func (t *tinyLFU) age() {
+	for i := range t.rows {
+		for j := range t.rows[i] {
+			t.rows[i][j] >>= 1
+		}
+	}
+	t.samples /= 2
+}
+
+// admit reports whether candidate should replace victim in the cache.
+func (t *tinyLFU) admit(candidate, victim string) bool {
+	return t.estimate(candidate) > t.estimate(victim)
+}
+
+// clone returns an independent copy of the sketch.
+func (t *tinyLFU) clone() *tinyLFU {
+	n := *t
+	for i := range t.rows {
+		n.rows[i] = append([]uint8(nil), t.rows[i]...)
+	}
+	return &n
+}
@@ -0,0 +1,69 @@
+package lru
+
+import "testing"
+
+func TestTinyLFUAdmit(t *testing.T) {
+	lru := NewTinyLFU(int64(len("hot")+len("v1")), 100, nil)
+	lru.Add("hot", String("v1"))
+	for i := 0; i < 5; i++ {
+		lru.Get("hot")
+	}
+	// 冷 key 只出现一次，不应挤掉热 key
+	if err := lru.AddChecked("new", String("v2")); err != ErrRejected {
+		t.Fatalf("expected ErrRejected but got %v", err)
+	}
+	if _, ok := lru.Get("hot"); !ok {
+		t.Fatalf("cold key evicted hot key")
+	}
+	if _, ok := lru.Get("new"); ok {
+		t.Fatalf("cold key should not be admitted")
+	}
+	// 访问次数足够多之后允许进入
+	for i := 0; i < 10; i++ {
+		lru.Get("new")
+	}
+	lru.Add("new", String("v2"))
+	if _, ok := lru.Get("new"); !ok {
+		t.Fatalf("warm key should be admitted")
+	}
+	if _, ok := lru.Get("hot"); ok || lru.Len() != 1 {
+		t.Fatalf("expected hot to be evicted")
+	}
+}
+
+func TestTinyLFUAge(t *testing.T) {
+	f := newTinyLFU(16)
+	for i := 0; i < 8; i++ {
+		f.record("k")
+	}
+	before := f.estimate("k")
+	f.age()
+	if after := f.estimate("k"); after != before/2 {
+		t.Fatalf("expected %d after aging but got %d", before/2, after)
+	}
+}
+
+func TestTinyLFUAdmitEveryVictim(t *testing.T) {
+	vetoes := 0
+	lru := NewWithOptions(WithMaxBytes(int64(len("cold1hot1"))), WithTinyLFU(100),
+		WithCanEvict(func(key string, value Value) bool {
+			vetoes++
+			return true
+		}))
+	lru.Add("cold", String("1"))
+	lru.Add("hot", String("1"))
+	for i := 0; i < 5; i++ {
+		lru.Get("hot")
+	}
+	for i := 0; i < 2; i++ {
+		lru.Get("new")
+	}
+	// new 比 cold 热但不如 hot，而放入它需要同时淘汰两者
+	lru.Add("new", String("123456"))
+	if _, ok := lru.Get("new"); ok || lru.Len() != 2 {
+		t.Fatalf("new should be compared against every entry it would evict")
+	}
+	if vetoes != 0 {
+		t.Fatalf("admission should not run CanEvict, ran it %d times", vetoes)
+	}
+}
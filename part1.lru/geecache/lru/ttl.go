@@ -0,0 +1,110 @@
+package lru
+
+import "time"
+
+// NoExpiration passed to AddWithTTL stores an entry that never expires, even
+// when the cache has a default TTL.
+const NoExpiration time.Duration = -1
+
+// now is replaced in tests to control the clock.
+var now = time.Now
+
+func (e *entry) expired(t time.Time) bool {
+	return !e.expire.IsZero() && !t.Before(e.expire)
+}
+
+// dead reports whether kv has passed its TTL or the cache's maxAge, and why.
+func (c *Cache) dead(kv *entry) (EvictionReason, bool) {
+	if kv.expire.IsZero() && c.maxAge == 0 {
+		return 0, false
+	}
+	t := now()
+	if kv.expired(t) {
+		return ReasonExpired, true
+	}
+	if c.maxAge != 0 && t.Sub(kv.created) >= c.maxAge {
+		return ReasonStale, true
+	}
+	return 0, false
+}
+
+// tracksAge reports whether entries need their insertion time recorded.
+func (c *Cache) tracksAge() bool {
+	return c.maxAge != 0 || c.minRetention != 0 || c.trackAccess
+}
+
+// removeStale drops entries older than maxAge from the cold end of the list.
+// It is opportunistic: a stale entry that was recently read sits nearer the
+// front and is only dropped when it is next looked up.
+func (c *Cache) removeStale() {
+	t := now()
+	for kv := c.ll.Back(); kv != nil; kv = c.ll.Back() {
+		if t.Sub(kv.created) < c.maxAge {
+			return
+		}
+		c.removeElement(kv, ReasonStale)
+	}
+}
+
+// AddWithTTL adds a value that expires after ttl. A zero ttl uses the
+// cache's default TTL and NoExpiration (or any negative ttl) means the entry
+// never expires. Expired entries are removed lazily when they are looked up.
+func (c *Cache) AddWithTTL(key string, value Value, ttl time.Duration) {
+	c.addChecked(key, value, c.expiry(ttl))
+}
+
+// UpdateTTL changes when key expires without re-adding its value and
+// reports whether key was present. The ttl counts from now and follows
+// AddWithTTL: 0 applies the default TTL and NoExpiration (or any negative
+// ttl) means the entry never expires; use Remove to drop it instead. The
+// entry keeps its place in the LRU order. An entry that has already expired
+// is removed and reported as absent.
+func (c *Cache) UpdateTTL(key string, ttl time.Duration) bool {
+	kv, ok := c.cache[key]
+	if !ok {
+		return false
+	}
+	if reason, dead := c.dead(kv); dead {
+		c.removeElement(kv, reason)
+		return false
+	}
+	c.setExpire(kv, c.expiry(ttl))
+	return true
+}
+
+// EntryInfo returns when key was last added and when it was last added or
+// returned by a promoting Get. It does not count as a use of the entry.
+// Both times are zero unless the cache was built WithAccessTimes, except
+// that created is also kept WithMaxAge or WithMinRetention.
+func (c *Cache) EntryInfo(key string) (created, accessed time.Time, ok bool) {
+	kv, ok := c.cache[key]
+	if !ok {
+		return
+	}
+	if reason, dead := c.dead(kv); dead {
+		c.removeElement(kv, reason)
+		return time.Time{}, time.Time{}, false
+	}
+	return kv.created, kv.accessed, true
+}
+
+// expiry turns a ttl into an absolute expiry time, applying the default TTL.
+func (c *Cache) expiry(ttl time.Duration) time.Time {
+	if ttl == 0 {
+		ttl = c.defaultTTL
+	}
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return now().Add(ttl)
+}
+
+// GetWithExpiry is like Get but also returns the absolute time the entry
+// expires at. A zero time.Time means the entry never expires.
+func (c *Cache) GetWithExpiry(key string) (value Value, expiresAt time.Time, ok bool) {
+	if kv := c.lookup(key, true); kv != nil {
+		value, ok = c.valueOf(kv)
+		return value, kv.expire, ok
+	}
+	return
+}
@@ -0,0 +1,215 @@
+package lru
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+// fakeClock sets the package clock to a fixed time. It returns a function
+// that advances the clock and one that restores time.Now.
+func fakeClock() (advance func(time.Duration), restore func()) {
+	current := time.Unix(1000, 0)
+	now = func() time.Time { return current }
+	return func(d time.Duration) { current = current.Add(d) }, func() { now = time.Now }
+}
+
+func TestAddWithTTL(t *testing.T) {
+	advance, restore := fakeClock()
+	defer restore()
+	lru := New(int64(0), nil)
+	lru.AddWithTTL("key1", String("1234"), time.Second)
+	lru.Add("key2", String("5678"))
+
+	advance(999 * time.Millisecond)
+	if _, ok := lru.Get("key1"); !ok {
+		t.Fatalf("key1 should not have expired yet")
+	}
+	advance(time.Millisecond)
+	if _, ok := lru.Get("key1"); ok {
+		t.Fatalf("key1 should have expired")
+	}
+	if _, ok := lru.Get("key2"); !ok || lru.Len() != 1 {
+		t.Fatalf("key2 without TTL should stay cached")
+	}
+}
+
+func TestGetWithExpiry(t *testing.T) {
+	advance, restore := fakeClock()
+	defer restore()
+	lru := New(int64(0), nil)
+	lru.AddWithTTL("key1", String("1234"), time.Minute)
+	lru.Add("key2", String("5678"))
+
+	_, expiresAt, ok := lru.GetWithExpiry("key1")
+	if !ok || !expiresAt.Equal(now().Add(time.Minute)) {
+		t.Fatalf("unexpected expiry %v for key1", expiresAt)
+	}
+	if _, expiresAt, ok = lru.GetWithExpiry("key2"); !ok || !expiresAt.IsZero() {
+		t.Fatalf("key2 should never expire but got %v", expiresAt)
+	}
+	advance(time.Minute)
+	if _, _, ok = lru.GetWithExpiry("key1"); ok {
+		t.Fatalf("key1 should have expired")
+	}
+}
+
+func TestDefaultTTL(t *testing.T) {
+	advance, restore := fakeClock()
+	defer restore()
+	lru := NewWithOptions(WithDefaultTTL(time.Minute))
+	lru.Add("key1", String("1"))
+	lru.AddWithTTL("key2", String("2"), NoExpiration)
+	lru.AddWithTTL("key3", String("3"), time.Hour)
+
+	advance(time.Minute)
+	if _, ok := lru.Get("key1"); ok {
+		t.Fatalf("key1 should expire after the default TTL")
+	}
+	if _, ok := lru.Get("key2"); !ok {
+		t.Fatalf("key2 added with NoExpiration should not expire")
+	}
+	if _, ok := lru.Get("key3"); !ok {
+		t.Fatalf("key3 should use its own TTL")
+	}
+}
+
+func TestMaxAge(t *testing.T) {
+	advance, restore := fakeClock()
+	defer restore()
+	lru := NewWithOptions(WithMaxAge(time.Minute))
+	lru.Add("key1", String("1"))
+
+	// 访问只影响 LRU 顺序，不会延长存活时间
+	advance(30 * time.Second)
+	if _, ok := lru.Get("key1"); !ok {
+		t.Fatalf("key1 should still be fresh")
+	}
+	advance(30 * time.Second)
+	if _, ok := lru.Get("key1"); ok {
+		t.Fatalf("key1 should be stale even though it was read recently")
+	}
+}
+
+func TestMaxAgeOnAdd(t *testing.T) {
+	advance, restore := fakeClock()
+	defer restore()
+	reasons := make([]EvictionReason, 0)
+	lru := NewWithOptions(WithMaxAge(time.Minute))
+	events := lru.Events()
+	lru.Add("key1", String("1"))
+	lru.Add("key2", String("2"))
+	advance(time.Minute)
+	lru.Add("key2", String("22"))
+	lru.Add("key3", String("3"))
+	for len(events) > 0 {
+		reasons = append(reasons, (<-events).Reason)
+	}
+	if lru.Len() != 2 || len(reasons) != 1 || reasons[0] != ReasonStale {
+		t.Fatalf("expected key1 to be dropped as stale, got %v", reasons)
+	}
+	if _, ok := lru.Get("key2"); !ok {
+		t.Fatalf("replacing key2's value should reset its age")
+	}
+}
+
+func TestMinRetention(t *testing.T) {
+	advance, restore := fakeClock()
+	defer restore()
+	lru := NewWithOptions(WithMaxBytes(int64(len("k1v1k2v2"))), WithMinRetention(time.Second))
+	lru.Add("k1", String("v1"))
+	advance(time.Second)
+	lru.Add("k2", String("v2"))
+	// k2 最久未访问但还在保留期内，应该淘汰 k1
+	lru.Get("k1")
+	lru.Add("k3", String("v3"))
+	if _, ok := lru.Get("k1"); ok {
+		t.Fatalf("k1 is past the retention window and should be evicted")
+	}
+	if _, ok := lru.Get("k2"); !ok {
+		t.Fatalf("k2 is within the retention window and should be kept")
+	}
+	// k2、k3 都在保留期内，退回淘汰最久未访问的 k3
+	lru.Add("k4", String("v4"))
+	if _, ok := lru.Get("k3"); ok || lru.Len() != 2 {
+		t.Fatalf("expected the true oldest entry to be evicted when all are young")
+	}
+}
+
+func TestUpdateTTL(t *testing.T) {
+	advance, restore := fakeClock()
+	defer restore()
+	lru := NewWithOptions(WithDefaultTTL(time.Hour))
+	lru.AddWithTTL("key1", String("1"), time.Second)
+	lru.Add("key2", String("2"))
+	lru.AddWithTTL("key3", String("3"), time.Second)
+
+	if !lru.UpdateTTL("key1", time.Minute) || lru.UpdateTTL("missing", time.Minute) {
+		t.Fatalf("UpdateTTL should report whether the key exists")
+	}
+	lru.UpdateTTL("key2", NoExpiration)
+	advance(time.Second)
+	if lru.UpdateTTL("key3", time.Minute) || lru.Len() != 2 {
+		t.Fatalf("an expired entry should be removed rather than extended")
+	}
+	if _, expiresAt, ok := lru.GetWithExpiry("key1"); !ok || !expiresAt.Equal(now().Add(59*time.Second)) {
+		t.Fatalf("key1 should expire a minute after UpdateTTL, got %v", expiresAt)
+	}
+	lru.UpdateTTL("key1", 0)
+	if _, expiresAt, _ := lru.GetWithExpiry("key1"); !expiresAt.Equal(now().Add(time.Hour)) {
+		t.Fatalf("a zero ttl should apply the default TTL, got %v", expiresAt)
+	}
+	advance(time.Hour)
+	if lru.RemoveExpired() != 1 {
+		t.Fatalf("key1 should be reaped by its updated expiry")
+	}
+	if _, expiresAt, ok := lru.GetWithExpiry("key2"); !ok || !expiresAt.IsZero() {
+		t.Fatalf("key2 should never expire")
+	}
+}
+
+func TestEntryInfo(t *testing.T) {
+	advance, restore := fakeClock()
+	defer restore()
+	lru := NewWithOptions(WithAccessTimes())
+	lru.Add("key1", String("1"))
+	added := now()
+	advance(time.Second)
+	lru.GetOpt("key1", false)
+	if created, accessed, ok := lru.EntryInfo("key1"); !ok || !created.Equal(added) || !accessed.Equal(added) {
+		t.Fatalf("a non-promoting Get should not update the access time, got %v %v", created, accessed)
+	}
+	lru.Get("key1")
+	if created, accessed, _ := lru.EntryInfo("key1"); !created.Equal(added) || !accessed.Equal(now()) {
+		t.Fatalf("Get should update only the access time, got %v %v", created, accessed)
+	}
+	if _, _, ok := lru.EntryInfo("missing"); ok {
+		t.Fatalf("missing keys should report ok=false")
+	}
+
+	plain := New(int64(0), nil)
+	plain.Add("key1", String("1"))
+	if created, accessed, ok := plain.EntryInfo("key1"); !ok || !created.IsZero() || !accessed.IsZero() {
+		t.Fatalf("times should be zero without WithAccessTimes")
+	}
+}
+
+func TestMinRetentionScanBound(t *testing.T) {
+	advance, restore := fakeClock()
+	defer restore()
+	lru := NewWithOptions(WithMaxEntries(victimScan+2), WithMinRetention(time.Second))
+	lru.Add("old", String("v"))
+	advance(time.Second)
+	for i := 0; i <= victimScan; i++ {
+		lru.Add("young"+strconv.Itoa(i), String("v"))
+	}
+	// old 最近被访问过，排在 victimScan 个年轻节点之后，扫描到此为止
+	lru.Get("old")
+	lru.Add("new", String("v"))
+	if _, ok := lru.GetOpt("young0", false); ok {
+		t.Fatalf("eviction should settle for the oldest young entry after %d candidates", victimScan)
+	}
+	if _, ok := lru.GetOpt("old", false); !ok {
+		t.Fatalf("old is beyond the scan bound and should be kept")
+	}
+}
@@ -0,0 +1,179 @@
+package lru
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Entry is a key and the value written for it, as handed to a WriteBack's
+// flush function.
+type Entry struct {
+	Key   string
+	Value Value
+}
+
+// WriteBack is a concurrency-safe write-back cache. Add stores the value and
+// marks the key dirty; dirty entries are handed to flush in batches, either
+// every interval by a background goroutine, by FlushNow, or by Close.
+//
+// A dirty entry that is evicted, including by expiry, is flushed on its own
+// before it leaves the cache. If that flush fails the write is kept in the
+// dirty set and retried by the next batch, so it is not lost, but Get no
+// longer sees it: until the retry succeeds the key misses here while its
+// write is still pending, and Dirty counts it. Remove discards the key's
+// pending write instead of flushing it.
+//
+// flush runs with the cache locked, so writes wait for it to finish.
+type WriteBack struct {
+	mu    sync.Mutex
+	lru   *Cache
+	flush func(entries []Entry) error
+	dirty map[string]Value // 还没写回后端的 key 及其最新值
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// NewWriteBack creates a write-back cache configured by opts that hands
+// dirty entries to flush. With interval > 0 a background goroutine flushes
+// every interval until Close is called. An OnEvicted callback set in opts
+// still runs, after any flush of the evicted entry.
+func NewWriteBack(flush func(entries []Entry) error, interval time.Duration, opts ...Option) *WriteBack {
+	w := &WriteBack{
+		lru:   NewWithOptions(opts...),
+		flush: flush,
+		dirty: make(map[string]Value),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	onEvicted := w.lru.OnEvicted
+	w.lru.OnEvicted = func(key string, value Value) {
+		w.evicted(key)
+		if onEvicted != nil {
+			onEvicted(key, value)
+		}
+	}
+	if interval > 0 {
+		go w.run(interval)
+	} else {
+		close(w.done)
+	}
+	return w
+}
+
+func (w *WriteBack) run(interval time.Duration) {
+	defer close(w.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			// 失败的条目仍留在 dirty 中，下一轮再试
+			w.FlushNow()
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// evicted flushes key's pending write, if any, as it leaves the cache.
+// It is called from OnEvicted with w.mu held. A write whose flush fails
+// stays in w.dirty for the next batch even though the entry is gone.
+func (w *WriteBack) evicted(key string) {
+	v, ok := w.dirty[key]
+	if !ok {
+		return
+	}
+	if w.flush([]Entry{{Key: key, Value: v}}) == nil {
+		delete(w.dirty, key)
+	}
+}
+
+// Add stores value and marks key dirty, returning the error from
+// AddChecked. A nil value or one rejected by Validate is dropped and not
+// written back. A value too large to cache, or kept out by the admission
+// filter, is still written back like an evicted one: it is flushed at once
+// if it replaces a cached value and otherwise by the next batch, but Get
+// will not find it.
+func (w *WriteBack) Add(key string, value Value) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	// 先登记再写入：写入引起的淘汰可能正好淘汰这个 key，此时需要把它写回
+	prev, had := w.dirty[key]
+	w.dirty[key] = value
+	err := w.lru.AddChecked(key, value)
+	if err != nil && err != ErrValueTooLarge && err != ErrRejected {
+		// 写入被拒绝，旧值仍在缓存中，恢复它的 dirty 状态
+		if had {
+			w.dirty[key] = prev
+		} else {
+			delete(w.dirty, key)
+		}
+	}
+	return err
+}
+
+// Get looks up a key's value.
+func (w *WriteBack) Get(key string) (Value, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lru.Get(key)
+}
+
+// Remove removes key from the cache, discarding its pending write, and
+// reports whether it was present.
+func (w *WriteBack) Remove(key string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.dirty, key)
+	return w.lru.Remove(key)
+}
+
+// Len returns the number of cache entries.
+func (w *WriteBack) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lru.Len()
+}
+
+// Dirty returns the number of writes not yet flushed.
+func (w *WriteBack) Dirty() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.dirty)
+}
+
+// FlushNow hands every dirty entry to flush in one batch, sorted by key, and
+// clears the dirty set if it succeeds. On error the entries stay dirty.
+func (w *WriteBack) FlushNow() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.dirty) == 0 {
+		return nil
+	}
+	entries := make([]Entry, 0, len(w.dirty))
+	for k, v := range w.dirty {
+		entries = append(entries, Entry{Key: k, Value: v})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	if err := w.flush(entries); err != nil {
+		return err
+	}
+	w.dirty = make(map[string]Value)
+	return nil
+}
+
+// Close stops the background flusher and flushes the remaining dirty
+// entries, returning the error from that flush. Later calls return the same
+// error. The cache can still be read after Close.
+func (w *WriteBack) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.stop)
+		<-w.done
+		w.closeErr = w.FlushNow()
+	})
+	return w.closeErr
+}
@@ -0,0 +1,142 @@
+package lru
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// backend records what a WriteBack flushed and can be made to fail.
+type backend struct {
+	batches [][]Entry
+	fail    bool
+}
+
+func (b *backend) flush(entries []Entry) error {
+	if b.fail {
+		return errors.New("backend down")
+	}
+	b.batches = append(b.batches, entries)
+	return nil
+}
+
+func TestWriteBackFlushNow(t *testing.T) {
+	b := &backend{}
+	w := NewWriteBack(b.flush, 0)
+	w.Add("k2", String("2"))
+	w.Add("k1", String("1"))
+	w.Add("k1", String("11"))
+	if w.Dirty() != 2 {
+		t.Fatalf("expected 2 dirty keys, got %d", w.Dirty())
+	}
+	if err := w.FlushNow(); err != nil {
+		t.Fatalf("FlushNow failed: %v", err)
+	}
+	want := [][]Entry{{{"k1", String("11")}, {"k2", String("2")}}}
+	if !reflect.DeepEqual(b.batches, want) || w.Dirty() != 0 {
+		t.Fatalf("unexpected batches %v", b.batches)
+	}
+	// 已写回的条目不会重复写回
+	if err := w.FlushNow(); err != nil || len(b.batches) != 1 {
+		t.Fatalf("clean entries should not be flushed again")
+	}
+	if v, ok := w.Get("k1"); !ok || v.(String) != "11" {
+		t.Fatalf("flushed entries should stay cached")
+	}
+}
+
+func TestWriteBackFlushError(t *testing.T) {
+	b := &backend{fail: true}
+	w := NewWriteBack(b.flush, 0)
+	w.Add("k1", String("1"))
+	if err := w.FlushNow(); err == nil || w.Dirty() != 1 {
+		t.Fatalf("a failed flush should keep entries dirty")
+	}
+	b.fail = false
+	if err := w.Close(); err != nil || len(b.batches) != 1 {
+		t.Fatalf("Close should flush the remaining entries, got %v", err)
+	}
+}
+
+func TestWriteBackEviction(t *testing.T) {
+	b := &backend{}
+	w := NewWriteBack(b.flush, 0, WithMaxEntries(1))
+	w.Add("k1", String("1"))
+	w.Add("k2", String("2"))
+	want := [][]Entry{{{"k1", String("1")}}}
+	if !reflect.DeepEqual(b.batches, want) {
+		t.Fatalf("evicted dirty entry should be flushed first, got %v", b.batches)
+	}
+	// 写回失败时保留在 dirty 中，不会丢失
+	b.fail = true
+	w.Add("k3", String("3"))
+	// 已离开缓存但仍待写回
+	if _, ok := w.Get("k2"); ok || w.Dirty() != 2 {
+		t.Fatalf("an entry whose flush failed should miss but stay dirty")
+	}
+	b.fail = false
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	want = append(want, []Entry{{"k2", String("2")}, {"k3", String("3")}})
+	if !reflect.DeepEqual(b.batches, want) {
+		t.Fatalf("unexpected batches %v", b.batches)
+	}
+}
+
+func TestWriteBackRemove(t *testing.T) {
+	b := &backend{}
+	w := NewWriteBack(b.flush, 0)
+	w.Add("k1", String("1"))
+	if !w.Remove("k1") || w.Dirty() != 0 {
+		t.Fatalf("Remove should discard the pending write")
+	}
+	w.Close()
+	if len(b.batches) != 0 {
+		t.Fatalf("removed entries should not be flushed, got %v", b.batches)
+	}
+}
+
+func TestWriteBackBackground(t *testing.T) {
+	b := &backend{}
+	w := NewWriteBack(b.flush, time.Millisecond)
+	defer w.Close()
+	w.Add("k1", String("1"))
+	deadline := time.Now().Add(time.Second)
+	for w.Dirty() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("background flusher did not flush k1")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWriteBackRejected(t *testing.T) {
+	b := &backend{}
+	w := NewWriteBack(b.flush, 0, WithMaxBytes(int64(len("k11"))),
+		WithValidate(func(key string, value Value) error {
+			if value.(String) == "bad" {
+				return errors.New("bad value")
+			}
+			return nil
+		}))
+	if err := w.Add("k1", String("bad")); err == nil || w.Dirty() != 0 {
+		t.Fatalf("a value rejected by Validate should be reported and not written back")
+	}
+	w.Add("k1", String("1"))
+	if err := w.Add("k1", String("too large")); err != ErrValueTooLarge {
+		t.Fatalf("expected ErrValueTooLarge but got %v", err)
+	}
+	// 旧值被移除，过大的新值立即写回
+	want := [][]Entry{{{"k1", String("too large")}}}
+	if _, ok := w.Get("k1"); ok || w.Dirty() != 0 || !reflect.DeepEqual(b.batches, want) {
+		t.Fatalf("an oversize overwrite should be flushed at once, got %v", b.batches)
+	}
+	if err := w.Add("k2", String("too large")); err != ErrValueTooLarge || w.Dirty() != 1 {
+		t.Fatalf("an oversize write should stay pending, got %v", err)
+	}
+	if err := w.Close(); err != nil || !reflect.DeepEqual(b.batches[1], []Entry{{"k2", String("too large")}}) {
+		t.Fatalf("the pending oversize write should be flushed by Close, got %v", b.batches)
+	}
+}